@@ -0,0 +1,12 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/heiytor/snap/snapcli"
+)
+
+func main() {
+	os.Exit(snapcli.Run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
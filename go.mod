@@ -2,4 +2,4 @@ module github.com/heiytor/snap
 
 go 1.22.3
 
-require gopkg.in/ini.v1 v1.67.0 // indirect
+require gopkg.in/ini.v1 v1.67.0
@@ -0,0 +1,251 @@
+// Package refs reads references from a git directory: loose refs stored as files under "refs/"
+// and the "packed-refs" file.
+package refs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// maxSymrefDepth is how many symbolic references [Resolve] follows before giving up, like git.
+const maxSymrefDepth = 5
+
+// searchOrder lists, in order, the formats tried by [Resolve] to expand a short name such as
+// "master" or "v1.0" into a full reference name.
+var searchOrder = []string{
+	"%s",
+	"refs/%s",
+	"refs/tags/%s",
+	"refs/heads/%s",
+	"refs/remotes/%s",
+	"refs/remotes/%s/HEAD",
+}
+
+// NotFoundError is returned by [Resolve] when a name doesn't match any reference, or when a
+// symbolic reference points to a reference that doesn't exist, such as HEAD in a repository
+// without commits.
+type NotFoundError struct {
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return "reference not found: " + e.Name
+}
+
+// BrokenRefError is returned by [Resolve] when the reference called Name exists but doesn't hold
+// an object ID or a symbolic reference, such as an empty or truncated loose reference file.
+type BrokenRefError struct {
+	Name string
+}
+
+func (e *BrokenRefError) Error() string {
+	return "broken ref: " + e.Name
+}
+
+// SymrefLoopError is returned by [Resolve] when following symbolic references from Name doesn't
+// reach an object ID within a few steps, usually because they form a loop.
+type SymrefLoopError struct {
+	Name string
+}
+
+func (e *SymrefLoopError) Error() string {
+	return "too many levels of symbolic references: " + e.Name
+}
+
+// IsObjectID reports whether s is a full hexadecimal object ID for the object format, either
+// "sha1" or "sha256".
+func IsObjectID(s, format string) bool {
+	length := 40
+	if format == "sha256" {
+		length = 64
+	}
+
+	if len(s) != length {
+		return false
+	}
+
+	return strings.Trim(strings.ToLower(s), "0123456789abcdef") == ""
+}
+
+// Resolve returns the object ID name points to in fsys, the contents of a git directory whose
+// objects are named with format, "sha1" or "sha256". Short names are expanded like git does, so
+// "master" finds "refs/heads/master" and "v1.0" finds "refs/tags/v1.0", and symbolic references
+// like HEAD are followed. Names that aren't valid reference names (see [ValidName]) are never
+// found.
+func Resolve(fsys fs.FS, name, format string) (string, error) {
+	packed, err := readPackedRefs(fsys)
+	if err != nil {
+		return "", err
+	}
+
+	for _, pattern := range searchOrder {
+		full := fmt.Sprintf(pattern, name)
+		if full != "HEAD" && !strings.HasPrefix(full, "refs/") && !isPseudoRef(full) || !ValidName(full) {
+			continue
+		}
+
+		oid, err := resolveFull(fsys, packed, full, format, 0)
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) && notFound.Name == full {
+			continue
+		}
+
+		return oid, err
+	}
+
+	return "", &NotFoundError{Name: name}
+}
+
+// resolveFull reads the reference called exactly name, following symbolic references.
+func resolveFull(fsys fs.FS, packed map[string]string, name, format string, depth int) (string, error) {
+	if depth > maxSymrefDepth {
+		return "", &SymrefLoopError{Name: name}
+	}
+
+	content, err := fs.ReadFile(fsys, name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrInvalid):
+		oid, ok := packed[name]
+		switch {
+		case !ok:
+			return "", &NotFoundError{Name: name}
+		case !IsObjectID(oid, format):
+			return "", &BrokenRefError{Name: name}
+		}
+
+		return strings.ToLower(oid), nil
+	case err != nil:
+		// A directory, such as "refs/heads" when resolving "heads", is not a reference.
+		if info, statErr := fs.Stat(fsys, name); statErr == nil && info.IsDir() {
+			return "", &NotFoundError{Name: name}
+		}
+
+		return "", err
+	}
+
+	line, _, _ := strings.Cut(string(content), "\n")
+	if target, ok := strings.CutPrefix(line, "ref: "); ok {
+		return resolveFull(fsys, packed, strings.TrimSpace(target), format, depth+1)
+	}
+
+	// FETCH_HEAD lines carry more fields after the object ID.
+	fields := strings.Fields(line)
+	if len(fields) == 0 || !IsObjectID(fields[0], format) {
+		return "", &BrokenRefError{Name: name}
+	}
+
+	return strings.ToLower(fields[0]), nil
+}
+
+// isPseudoRef reports whether name is an all-caps reference stored at the top of the git
+// directory, like HEAD, ORIG_HEAD or FETCH_HEAD.
+func isPseudoRef(name string) bool {
+	if !strings.HasSuffix(name, "HEAD") {
+		return false
+	}
+
+	return strings.Trim(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ_") == ""
+}
+
+// ValidName reports whether name is a well-formed reference name, following the rules of "git
+// check-ref-format": no component may be empty, start with "." or end with ".lock", and the name
+// can't contain "..", "@{", control characters, spaces or any of ~^:?*[\, start or end with "/"
+// or end with ".". Names with a single component, such as HEAD, are accepted.
+func ValidName(name string) bool {
+	if name == "" || name == "@" || strings.HasSuffix(name, ".") {
+		return false
+	}
+
+	if strings.Contains(name, "..") || strings.Contains(name, "@{") {
+		return false
+	}
+
+	if strings.ContainsFunc(name, func(r rune) bool {
+		return r < ' ' || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r)
+	}) {
+		return false
+	}
+
+	for _, component := range strings.Split(name, "/") {
+		if component == "" || component[0] == '.' || strings.HasSuffix(component, ".lock") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// List returns the full names, such as "refs/heads/master", of every reference in fsys, the
+// contents of a git directory, whose name starts with prefix. Names are sorted and unique. Lock
+// files left next to loose references, like "refs/heads/master.lock", are skipped.
+func List(fsys fs.FS, prefix string) ([]string, error) {
+	names := []string{}
+
+	err := fs.WalkDir(fsys, "refs", func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && name == "refs":
+			return fs.SkipAll
+		case err != nil:
+			return err
+		case d.IsDir(), !strings.HasPrefix(name, prefix):
+		case strings.HasSuffix(name, ".lock"):
+			// A lock file is a reference being written, not one of its own.
+		default:
+			names = append(names, name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := readPackedRefs(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range packed {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// readPackedRefs parses the "packed-refs" file of fsys into a map of reference names to object
+// IDs. A missing file yields an empty map.
+func readPackedRefs(fsys fs.FS) (map[string]string, error) {
+	refs := map[string]string{}
+
+	content, err := fs.ReadFile(fsys, "packed-refs")
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return refs, nil
+	case err != nil:
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// "#" starts the header and "^" the peeled object of the preceding annotated tag.
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+
+		if oid, name, ok := strings.Cut(line, " "); ok {
+			refs[name] = oid
+		}
+	}
+
+	return refs, scanner.Err()
+}
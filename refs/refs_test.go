@@ -0,0 +1,195 @@
+package refs
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const (
+	oid1 = "1111111111111111111111111111111111111111"
+	oid2 = "2222222222222222222222222222222222222222"
+	oid3 = "3333333333333333333333333333333333333333"
+)
+
+// gitDir returns the contents of a git directory holding the given files.
+func gitDir(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	return fsys
+}
+
+func TestResolve(t *testing.T) {
+	packed := "# pack-refs with: peeled fully-peeled sorted \n" +
+		oid2 + " refs/heads/packed\n" +
+		oid3 + " refs/tags/v1\n" +
+		oid2 + " refs/heads/emptied\n" +
+		strings.ToUpper("abcdef0123abcdef0123abcdef0123abcdef0123") + " refs/heads/upper-packed\n" +
+		"^" + oid1 + "\n" +
+		"zzz refs/heads/bad-packed\n"
+
+	fsys := gitDir(map[string]string{
+		"HEAD":                      "ref: refs/heads/master\n",
+		"ORIG_HEAD":                 oid2 + "\n",
+		"FETCH_HEAD":                oid3 + "\t\tbranch 'master' of example.com\n",
+		"packed-refs":               packed,
+		"refs/heads/master":         oid1 + "\n",
+		"refs/heads/upper":          strings.ToUpper("abcdef0123abcdef0123abcdef0123abcdef0123") + "\n",
+		"refs/heads/shadowed":       oid1 + "\n",
+		"refs/tags/shadowed":        oid2 + "\n",
+		"refs/heads/empty":          "",
+		"refs/heads/emptied":        "",
+		"refs/heads/blank":          "\n",
+		"refs/heads/spaces":         "   \n",
+		"refs/heads/garbage":        "garbage\n",
+		"refs/heads/short":          "1111111\n",
+		"refs/heads/dangling":       "ref: refs/heads/missing\n",
+		"refs/heads/to-broken":      "ref: refs/heads/garbage\n",
+		"refs/heads/loop":           "ref: refs/heads/loop\n",
+		"refs/heads/master.lock":    oid2 + "\n",
+		"refs/remotes/origin/HEAD":  "ref: refs/remotes/origin/main\n",
+		"refs/remotes/origin/main":  oid3 + "\n",
+		"refs/remotes/origin/extra": oid2 + "\n",
+	})
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr any // wantErr is a pointer to the expected error type, if any.
+	}{
+		{name: "HEAD", want: oid1},
+		{name: "master", want: oid1},
+		{name: "heads/master", want: oid1},
+		{name: "refs/heads/master", want: oid1},
+		{name: "upper", want: "abcdef0123abcdef0123abcdef0123abcdef0123"},
+		{name: "upper-packed", want: "abcdef0123abcdef0123abcdef0123abcdef0123"},
+		{name: "packed", want: oid2},
+		{name: "v1", want: oid3},
+		{name: "tags/v1", want: oid3},
+		{name: "shadowed", want: oid2}, // Tags come before branches.
+		{name: "ORIG_HEAD", want: oid2},
+		{name: "FETCH_HEAD", want: oid3},
+		{name: "origin", want: oid3},
+		{name: "origin/extra", want: oid2},
+
+		{name: "nope", wantErr: new(*NotFoundError)},
+		{name: "heads", wantErr: new(*NotFoundError)},
+		{name: "dangling", wantErr: new(*NotFoundError)},
+		{name: "config", wantErr: new(*NotFoundError)},
+
+		{name: "empty", wantErr: new(*BrokenRefError)},
+		{name: "emptied", wantErr: new(*BrokenRefError)}, // An empty loose file still shadows packed-refs.
+		{name: "blank", wantErr: new(*BrokenRefError)},
+		{name: "spaces", wantErr: new(*BrokenRefError)},
+		{name: "garbage", wantErr: new(*BrokenRefError)},
+		{name: "short", wantErr: new(*BrokenRefError)},
+		{name: "to-broken", wantErr: new(*BrokenRefError)},
+		{name: "bad-packed", wantErr: new(*BrokenRefError)},
+		{name: "master", format: "sha256", wantErr: new(*BrokenRefError)},
+
+		// Invalid reference names are never found, rather than failing in io/fs.
+		{name: "refs/../x", wantErr: new(*NotFoundError)},
+		{name: "foo/", wantErr: new(*NotFoundError)},
+		{name: "/master", wantErr: new(*NotFoundError)},
+		{name: "master.lock", wantErr: new(*NotFoundError)},
+		{name: "", wantErr: new(*NotFoundError)},
+
+		{name: "loop", wantErr: new(*SymrefLoopError)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := tt.format
+			if format == "" {
+				format = "sha1"
+			}
+
+			got, err := Resolve(fsys, tt.name, format)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.As(err, tt.wantErr) {
+					t.Fatalf("Resolve(%q) = %q, %v; want a %T", tt.name, got, err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("Resolve(%q) failed: %v", tt.name, err)
+			case got != tt.want:
+				t.Fatalf("Resolve(%q) = %q; want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	fsys := gitDir(map[string]string{
+		"HEAD":                 "ref: refs/heads/master\n",
+		"packed-refs":          oid2 + " refs/heads/master\n" + oid3 + " refs/tags/v1\n",
+		"refs/heads/master":    oid1 + "\n",
+		"refs/heads/feature/x": oid1 + "\n",
+		"refs/heads/dev.lock":  oid1 + "\n",
+		"refs/tags/v2":         oid2 + "\n",
+	})
+
+	tests := []struct {
+		fsys   fstest.MapFS
+		prefix string
+		want   []string
+	}{
+		{fsys: fsys, prefix: "", want: []string{"refs/heads/feature/x", "refs/heads/master", "refs/tags/v1", "refs/tags/v2"}},
+		{fsys: fsys, prefix: "refs/heads/", want: []string{"refs/heads/feature/x", "refs/heads/master"}},
+		{fsys: fsys, prefix: "refs/tags/", want: []string{"refs/tags/v1", "refs/tags/v2"}},
+		{fsys: fsys, prefix: "refs/remotes/", want: []string{}},
+		{fsys: gitDir(map[string]string{"HEAD": "ref: refs/heads/master\n"}), prefix: "", want: []string{}},
+		{fsys: gitDir(map[string]string{"packed-refs": oid1 + " refs/heads/only-packed\n"}), prefix: "", want: []string{"refs/heads/only-packed"}},
+	}
+
+	for _, tt := range tests {
+		got, err := List(tt.fsys, tt.prefix)
+		if err != nil {
+			t.Fatalf("List(%q) failed: %v", tt.prefix, err)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("List(%q) = %q; want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestValidName(t *testing.T) {
+	tests := map[string]bool{
+		"HEAD":                 true,
+		"refs/heads/master":    true,
+		"refs/heads/feature/x": true,
+		"refs/heads/a-b_c":     true,
+		"":                     false,
+		"@":                    false,
+		"refs/heads/a..b":      false,
+		"refs/heads/a b":       false,
+		"refs/heads/a~1":       false,
+		"refs/heads/a^":        false,
+		"refs/heads/a:b":       false,
+		"refs/heads/a?":        false,
+		"refs/heads/a*":        false,
+		"refs/heads/a[":        false,
+		"refs/heads/a\\b":      false,
+		"refs/heads/a@{1}":     false,
+		"refs/heads/a\tb":      false,
+		"refs/heads/.hidden":   false,
+		"refs/heads/a.lock":    false,
+		"refs/heads/a.":        false,
+		"refs/heads/":          false,
+		"/refs/heads/a":        false,
+		"refs//heads":          false,
+	}
+
+	for name, want := range tests {
+		if got := ValidName(name); got != want {
+			t.Errorf("ValidName(%q) = %v; want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// splitConfigKey splits a "section[.subsection].name" key, as used on the command line, into
+// the section and key names of [GitRepository.Config]. Subsections are written the way they
+// appear in the config file, as `section "subsection"`.
+func splitConfigKey(key string) (string, string, error) {
+	first, last := strings.Index(key, "."), strings.LastIndex(key, ".")
+	switch {
+	case first <= 0:
+		return "", "", &InvalidConfigKeyError{Key: key, Reason: "key does not contain a section"}
+	case last == len(key)-1:
+		return "", "", &InvalidConfigKeyError{Key: key, Reason: "key does not contain variable name"}
+	}
+
+	section, name := key[:first], key[last+1:]
+	if first != last {
+		section += ` "` + key[first+1:last] + `"`
+	}
+
+	return section, name, nil
+}
+
+// configOverrideKey normalizes a section and key name into the key of
+// [GitRepository.overrides], and is how entries of the "config" file are matched too. Section and
+// key names are case-insensitive, subsections are not.
+func configOverrideKey(section, key string) string {
+	name, subsection, _ := strings.Cut(section, " ")
+	return strings.ToLower(name) + " " + subsection + "\n" + strings.ToLower(key)
+}
+
+// parseConfigOverrides turns "key=value" pairs given through [WithConfig] into the overrides
+// consulted by [GitRepository.ConfigValue].
+func parseConfigOverrides(pairs [][2]string) (map[string]string, error) {
+	overrides := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		section, key, err := splitConfigKey(pair[0])
+		if err != nil {
+			return nil, err
+		}
+
+		overrides[configOverrideKey(section, key)] = pair[1]
+	}
+
+	return overrides, nil
+}
+
+// globalConfigFiles returns the configuration files git reads before the one of the repository,
+// in that order: GIT_CONFIG_SYSTEM or "/etc/gitconfig", unless GIT_CONFIG_NOSYSTEM is set, then
+// GIT_CONFIG_GLOBAL or both "$XDG_CONFIG_HOME/git/config" and "~/.gitconfig".
+func globalConfigFiles() []string {
+	files := []string{}
+	if !isTrue(os.Getenv("GIT_CONFIG_NOSYSTEM")) {
+		system, ok := os.LookupEnv("GIT_CONFIG_SYSTEM")
+		if !ok {
+			system = "/etc/gitconfig"
+		}
+
+		files = append(files, system)
+	}
+
+	if global, ok := os.LookupEnv("GIT_CONFIG_GLOBAL"); ok {
+		return append(files, global)
+	}
+
+	home, _ := os.UserHomeDir()
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" && home != "" {
+		xdg = filepath.Join(home, ".config")
+	}
+
+	if xdg != "" {
+		files = append(files, filepath.Join(xdg, "git", "config"))
+	}
+	if home != "" {
+		files = append(files, filepath.Join(home, ".gitconfig"))
+	}
+
+	return files
+}
+
+// loadGlobalConfig parses the files of [globalConfigFiles] that exist, in the same order.
+func loadGlobalConfig() ([]*ini.File, error) {
+	configs := []*ini.File{}
+	for _, name := range globalConfigFiles() {
+		content, err := os.ReadFile(name)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		config, err := ini.Load(content)
+		if err != nil {
+			return nil, &BadConfigFileError{Path: name, Err: err}
+		}
+
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
+
+// isTrue reports whether a configuration value is a true boolean, as spelled by git: "true",
+// "yes", "on" or a non-zero number.
+func isTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on":
+		return true
+	}
+
+	n, err := strconv.Atoi(value)
+	return err == nil && n != 0
+}
+
+// isFalse reports whether a configuration value is a false boolean, as spelled by git: "false",
+// "no", "off" or zero. Unlike the negation of [isTrue], it is false for unset values.
+func isFalse(value string) bool {
+	switch strings.ToLower(value) {
+	case "false", "no", "off":
+		return true
+	}
+
+	n, err := strconv.Atoi(value)
+	return err == nil && n == 0
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSplitConfigKey(t *testing.T) {
+	tests := []struct {
+		key         string
+		wantSection string
+		wantName    string
+		wantErr     bool
+	}{
+		{key: "core.bare", wantSection: "core", wantName: "bare"},
+		{key: "Core.Bare", wantSection: "Core", wantName: "Bare"},
+		{key: "remote.origin.url", wantSection: `remote "origin"`, wantName: "url"},
+		{key: "a.b.c.d", wantSection: `a "b.c"`, wantName: "d"},
+		{key: "a..b", wantSection: `a ""`, wantName: "b"},
+		{key: "core", wantErr: true},
+		{key: ".bare", wantErr: true},
+		{key: "core.", wantErr: true},
+		{key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		section, name, err := splitConfigKey(tt.key)
+		switch {
+		case tt.wantErr:
+			var keyErr *InvalidConfigKeyError
+			if !errors.As(err, &keyErr) {
+				t.Errorf("splitConfigKey(%q) = %q, %q, %v; want an InvalidConfigKeyError", tt.key, section, name, err)
+			}
+		case err != nil:
+			t.Errorf("splitConfigKey(%q) failed: %v", tt.key, err)
+		case section != tt.wantSection || name != tt.wantName:
+			t.Errorf("splitConfigKey(%q) = %q, %q; want %q, %q", tt.key, section, name, tt.wantSection, tt.wantName)
+		}
+	}
+}
+
+func TestConfigOverrideKey(t *testing.T) {
+	tests := []struct {
+		a, b  [2]string
+		equal bool
+	}{
+		{a: [2]string{"core", "bare"}, b: [2]string{"CORE", "Bare"}, equal: true},
+		{a: [2]string{`remote "origin"`, "url"}, b: [2]string{`Remote "origin"`, "URL"}, equal: true},
+		{a: [2]string{`remote "origin"`, "url"}, b: [2]string{`remote "Origin"`, "url"}, equal: false},
+		{a: [2]string{`a "b.c"`, "d"}, b: [2]string{"a", "b.c.d"}, equal: false},
+		{a: [2]string{"core", "bare"}, b: [2]string{"core", "editor"}, equal: false},
+	}
+
+	for _, tt := range tests {
+		a, b := configOverrideKey(tt.a[0], tt.a[1]), configOverrideKey(tt.b[0], tt.b[1])
+		if (a == b) != tt.equal {
+			t.Errorf("configOverrideKey(%q) == configOverrideKey(%q) is %v; want %v", tt.a, tt.b, a == b, tt.equal)
+		}
+	}
+}
+
+func TestConfigValue(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config"), "[core]\n"+
+		"\trepositoryformatversion = 0\n"+
+		"\tEditor = vim\n"+
+		"\teditor = nano\n"+
+		"[Remote \"origin\"]\n"+
+		"\tURL = https://example.com/a.git\n"+
+		"[remote \"Origin\"]\n"+
+		"\turl = https://example.com/b.git\n"+
+		"[a \"b.c\"]\n"+
+		"\td = subsection\n"+
+		"[user]\n"+
+		"\tname = From File\n"+
+		"\temail = file@example.com\n")
+
+	repo, err := Open(dir, WithBare(), WithConfig("USER.Name", "From Override"), WithConfig("user.missing", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		section, key string
+		want         string
+		wantOK       bool
+	}{
+		{section: "core", key: "editor", want: "nano", wantOK: true}, // The last entry wins.
+		{section: "CORE", key: "EDITOR", want: "nano", wantOK: true},
+		{section: `remote "origin"`, key: "url", want: "https://example.com/a.git", wantOK: true},
+		{section: `REMOTE "origin"`, key: "Url", want: "https://example.com/a.git", wantOK: true},
+		{section: `remote "Origin"`, key: "url", want: "https://example.com/b.git", wantOK: true},
+		{section: `remote "ORIGIN"`, key: "url"},
+		{section: `a "b.c"`, key: "d", want: "subsection", wantOK: true},
+		{section: "a", key: "d"},
+		{section: "user", key: "name", want: "From Override", wantOK: true},
+		{section: "user", key: "email", want: "file@example.com", wantOK: true},
+		{section: "user", key: "missing", want: "", wantOK: true},
+		{section: "user", key: "nope"},
+	}
+
+	for _, tt := range tests {
+		got, ok := repo.lookupConfig(tt.section, tt.key)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("lookupConfig(%q, %q) = %q, %v; want %q, %v", tt.section, tt.key, got, ok, tt.want, tt.wantOK)
+		}
+
+		if got := repo.ConfigValue(tt.section, tt.key); got != tt.want {
+			t.Errorf("ConfigValue(%q, %q) = %q; want %q", tt.section, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestGlobalConfigFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "")
+	t.Setenv("GIT_CONFIG_SYSTEM", "/etc/snap-system")
+	t.Setenv("GIT_CONFIG_GLOBAL", "") // Restored after the test, unlike os.Unsetenv alone.
+	os.Unsetenv("GIT_CONFIG_GLOBAL")
+
+	want := []string{"/etc/snap-system", filepath.Join(home, ".config", "git", "config"), filepath.Join(home, ".gitconfig")}
+	if got := globalConfigFiles(); !slices.Equal(got, want) {
+		t.Errorf("globalConfigFiles() = %q; want %q", got, want)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "true")
+	want = []string{filepath.Join("/xdg", "git", "config"), filepath.Join(home, ".gitconfig")}
+	if got := globalConfigFiles(); !slices.Equal(got, want) {
+		t.Errorf("globalConfigFiles() = %q; want %q", got, want)
+	}
+
+	t.Setenv("GIT_CONFIG_GLOBAL", "/global")
+	if got := globalConfigFiles(); !slices.Equal(got, []string{"/global"}) {
+		t.Errorf("globalConfigFiles() = %q; want only GIT_CONFIG_GLOBAL", got)
+	}
+}
+
+func TestGlobalConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "system"), "[user]\n\tname = System\n\temail = system@example.com\n[core]\n\teditor = ed\n")
+	writeFile(t, filepath.Join(dir, "global"), "[User]\n\tName = Global\n[core]\n\tpager = more\n"+
+		"\trepositoryformatversion = 2\n[extensions]\n\tobjectformat = md5\n")
+	writeFile(t, filepath.Join(dir, "repo", "config"), "[core]\n\trepositoryformatversion = 0\n\tpager = most\n")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "")
+	t.Setenv("GIT_CONFIG_SYSTEM", filepath.Join(dir, "system"))
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(dir, "global"))
+
+	// The format of the repository is never taken from the global configuration.
+	repo, err := Open(filepath.Join(dir, "repo"), WithBare(), WithConfig("core.editor", "nano"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	global, err := OpenGlobal(WithConfig("core.editor", "nano"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		repo         *GitRepository
+		section, key string
+		want         string
+	}{
+		{repo: repo, section: "user", key: "email", want: "system@example.com"},
+		{repo: repo, section: "user", key: "name", want: "Global"},
+		{repo: repo, section: "core", key: "pager", want: "most"},
+		{repo: repo, section: "core", key: "editor", want: "nano"},
+		{repo: global, section: "user", key: "name", want: "Global"},
+		{repo: global, section: "core", key: "pager", want: "more"},
+		{repo: global, section: "core", key: "editor", want: "nano"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.repo.ConfigValue(tt.section, tt.key); got != tt.want {
+			t.Errorf("ConfigValue(%q, %q) = %q; want %q", tt.section, tt.key, got, tt.want)
+		}
+	}
+
+	if format := repo.ObjectFormat(); format != "sha1" {
+		t.Errorf("ObjectFormat() = %q; want sha1", format)
+	}
+
+	writeFile(t, filepath.Join(dir, "global"), "[user\n")
+	var fileErr *BadConfigFileError
+	if _, err := Open(filepath.Join(dir, "repo"), WithBare()); !errors.As(err, &fileErr) || fileErr.Path != filepath.Join(dir, "global") {
+		t.Errorf("Open with a malformed global configuration = %v; want a BadConfigFileError", err)
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"os"
+)
+
+// Editor returns the command used to edit commit messages and other text: GIT_EDITOR, then
+// "core.editor", then VISUAL and EDITOR, and finally "vi". Like git, VISUAL is ignored and there
+// is no default on a dumb terminal.
+func (g *GitRepository) Editor() (string, error) {
+	dumb := os.Getenv("TERM") == "" || os.Getenv("TERM") == "dumb"
+
+	if editor := os.Getenv("GIT_EDITOR"); editor != "" {
+		return editor, nil
+	}
+
+	if editor := g.ConfigValue("core", "editor"); editor != "" {
+		return editor, nil
+	}
+
+	if editor := os.Getenv("VISUAL"); editor != "" && !dumb {
+		return editor, nil
+	}
+
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor, nil
+	}
+
+	if dumb {
+		return "", errors.New("terminal is dumb, but EDITOR unset")
+	}
+
+	return "vi", nil
+}
+
+// Pager returns the command output is paged through: GIT_PAGER, then "core.pager", then PAGER,
+// and finally "less". It returns "cat" when paging has been disabled with an empty value.
+func (g *GitRepository) Pager() string {
+	pager, ok := os.LookupEnv("GIT_PAGER")
+	if !ok {
+		pager, ok = g.lookupConfig("core", "pager")
+	}
+	if !ok {
+		pager, ok = os.LookupEnv("PAGER")
+	}
+	if !ok {
+		pager = "less"
+	}
+
+	if pager == "" {
+		return "cat"
+	}
+
+	return pager
+}
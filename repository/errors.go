@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingConfiguration  = errors.New("configuration file missing")
+	ErrGitRepositoryNotFound = errors.New("not a git repository (or any of the parent directories): .git")
+	ErrUnsupportedFormat     = errors.New("unsupported repository format")
+	ErrReadOnly              = errors.New("repository is read-only")
+)
+
+// RepositoryNotFoundError is returned by [Open] when no ".git" is found from Path up to the root
+// directory. It matches [ErrGitRepositoryNotFound] with [errors.Is].
+type RepositoryNotFoundError struct {
+	Path string // Path is the directory where the search started.
+}
+
+func (e *RepositoryNotFoundError) Error() string {
+	return ErrGitRepositoryNotFound.Error()
+}
+
+func (e *RepositoryNotFoundError) Is(target error) bool {
+	return target == ErrGitRepositoryNotFound
+}
+
+// NotExistError reports that Path does not exist. It matches [fs.ErrNotExist] with [errors.Is].
+type NotExistError struct {
+	Path string
+}
+
+func (e *NotExistError) Error() string {
+	return e.Path + ": no such file or directory"
+}
+
+func (e *NotExistError) Is(target error) bool {
+	return target == fs.ErrNotExist
+}
+
+// NotDirectoryError reports that Path exists but a directory was expected.
+type NotDirectoryError struct {
+	Path string
+}
+
+func (e *NotDirectoryError) Error() string {
+	return e.Path + ": is not a directory"
+}
+
+// InvalidGitFileError reports a ".git" file at Path without a "gitdir: <path>" line.
+type InvalidGitFileError struct {
+	Path string
+}
+
+func (e *InvalidGitFileError) Error() string {
+	return e.Path + ": invalid gitfile format"
+}
+
+// UnsupportedFormatError is returned by [Open] when the repository declares a
+// "core.repositoryformatversion" or an extension snap doesn't understand. It matches
+// [ErrUnsupportedFormat] with [errors.Is].
+type UnsupportedFormatError struct {
+	Version   int    // Version is the declared "core.repositoryformatversion".
+	Extension string // Extension is the unknown "extensions.*" entry, as "key=value", if any.
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	if e.Extension != "" {
+		return "unknown repository extension: " + e.Extension
+	}
+
+	return "unsupported repository format version " + strconv.Itoa(e.Version)
+}
+
+func (e *UnsupportedFormatError) Is(target error) bool {
+	return target == ErrUnsupportedFormat
+}
+
+// UnknownObjectFormatError is returned by [Init] when [WithObjectFormat] names an unknown hash
+// algorithm.
+type UnknownObjectFormatError struct {
+	Format string
+}
+
+func (e *UnknownObjectFormatError) Error() string {
+	return "unknown hash algorithm '" + e.Format + "'"
+}
+
+// ObjectFormatMismatchError is returned by [Init] when [WithObjectFormat] asks for a different hash
+// algorithm than the one of the existing repository being reinitialized.
+type ObjectFormatMismatchError struct {
+	Existing  string
+	Requested string
+}
+
+func (e *ObjectFormatMismatchError) Error() string {
+	return "attempt to reinitialize repository with different hash"
+}
+
+// InvalidBranchNameError is returned by [Init] when the initial branch, given through
+// [WithDefaultBranch] or "init.defaultBranch", is not a valid reference name.
+type InvalidBranchNameError struct {
+	Name string
+}
+
+func (e *InvalidBranchNameError) Error() string {
+	return "invalid initial branch name: '" + e.Name + "'"
+}
+
+// BadConfigValueError is returned when a configuration value can't be parsed, such as a
+// "core.repositoryformatversion" that is not a number.
+type BadConfigValueError struct {
+	Key   string // Key is the configuration key, in "section[.subsection].name" form.
+	Value string
+}
+
+func (e *BadConfigValueError) Error() string {
+	return "bad numeric config value '" + e.Value + "' for '" + e.Key + "'"
+}
+
+// BadConfigFileError is returned by [Open], [OpenGlobal] and [Init] when a global or system
+// configuration file can't be parsed.
+type BadConfigFileError struct {
+	Path string
+	Err  error
+}
+
+func (e *BadConfigFileError) Error() string {
+	return "bad config file " + e.Path + ": " + strings.TrimSpace(e.Err.Error())
+}
+
+func (e *BadConfigFileError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidConfigKeyError is returned by [Open] and [Init] when a key given to [WithConfig] is not
+// of the form "section[.subsection].name".
+type InvalidConfigKeyError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidConfigKeyError) Error() string {
+	return e.Reason + ": " + e.Key
+}
+
+// staleLockAge is how old a lock file must be before [LockedError] reports it as stale. Writes
+// hold their lock for milliseconds, so anything older was almost certainly left behind by a
+// crash.
+const staleLockAge = 10 * time.Minute
+
+// LockedError is returned when writing a file whose "<name>.lock" already exists, meaning another
+// process is writing it. It matches [fs.ErrExist] with [errors.Is].
+type LockedError struct {
+	Path string        // Path is the lock file.
+	Age  time.Duration // Age is how long ago the lock file was last modified.
+}
+
+// Stale reports whether the lock file is old enough that its owner has probably crashed.
+func (e *LockedError) Stale() bool {
+	return e.Age >= staleLockAge
+}
+
+func (e *LockedError) Error() string {
+	msg := "Unable to create '" + e.Path + "': File exists.\n\n"
+	if e.Stale() {
+		return msg + "The lock file is " + e.Age.Truncate(time.Minute).String() + " old, so the snap process that\n" +
+			"created it has probably crashed: remove the file manually to continue."
+	}
+
+	return msg + "Another snap process seems to be running in this repository.\n" +
+		"Please make sure all processes are terminated then try again. If it\n" +
+		"still fails, a snap process may have crashed in this repository\n" +
+		"earlier: remove the file manually to continue."
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == fs.ErrExist
+}
+
+// InvalidIdentError is returned by [GitRepository.AuthorIdent] and
+// [GitRepository.CommitterIdent] when no acceptable name or email can be found.
+type InvalidIdentError struct {
+	Reason string
+}
+
+func (e *InvalidIdentError) Error() string {
+	return e.Reason
+}
+
+// InvalidDateError is returned when GIT_AUTHOR_DATE or GIT_COMMITTER_DATE holds a date that can't
+// be parsed.
+type InvalidDateError struct {
+	Date string
+}
+
+func (e *InvalidDateError) Error() string {
+	return "invalid date format: " + e.Date
+}
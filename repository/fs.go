@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteFS is an [fs.FS] that can also be modified. A [GitRepository] only needs an [fs.FS] to be read,
+// writes are performed through this interface. Names follow the [fs.FS] conventions: they are
+// slash-separated and relative to the root of the filesystem.
+type WriteFS interface {
+	fs.FS
+
+	// MkdirAll creates the directory name along with any necessary parents, like [os.MkdirAll].
+	MkdirAll(name string, perm fs.FileMode) error
+	// WriteFile replaces the file name with data, creating it if necessary. The replacement
+	// must be atomic: readers, and the file left behind by a crash, see either the old or the
+	// new content, never a partial write.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// Remove removes the file or empty directory name, like [os.Remove].
+	Remove(name string) error
+}
+
+// SyncFS is a [WriteFS] that can also flush files to stable storage, for the writes selected by
+// "core.fsync".
+type SyncFS interface {
+	WriteFS
+
+	// WriteFileSync is like WriteFile, but data is flushed to stable storage before it replaces
+	// name.
+	WriteFileSync(name string, data []byte, perm fs.FileMode) error
+}
+
+// dirFS is the [SyncFS] returned by [DirFS].
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+// DirFS returns a [SyncFS] for the tree of files rooted at the directory dir, backed by the
+// operating system. Reads behave exactly like [os.DirFS].
+func DirFS(dir string) SyncFS {
+	return &dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+// path converts name to a path on the operating system.
+func (d *dirFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	return filepath.Join(d.dir, filepath.FromSlash(name)), nil
+}
+
+func (d *dirFS) MkdirAll(name string, perm fs.FileMode) error {
+	path, err := d.path("mkdir", name)
+	if err != nil {
+		return err
+	}
+
+	return os.MkdirAll(path, perm)
+}
+
+func (d *dirFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return d.writeFile(name, data, perm, false)
+}
+
+func (d *dirFS) WriteFileSync(name string, data []byte, perm fs.FileMode) error {
+	return d.writeFile(name, data, perm, true)
+}
+
+// writeFile follows git's lockfile protocol: data is written to "<name>.lock", created
+// exclusively so concurrent writers fail with a [LockedError] instead of clobbering each
+// other, and then renamed over name. With sync, the file and its directory are flushed around
+// the rename.
+func (d *dirFS) writeFile(name string, data []byte, perm fs.FileMode, sync bool) error {
+	path, err := d.path("write", name)
+	if err != nil {
+		return err
+	}
+
+	lock := path + ".lock"
+
+	f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	switch {
+	case errors.Is(err, fs.ErrExist):
+		lockErr := &LockedError{Path: lock}
+		if info, err := os.Stat(lock); err == nil {
+			lockErr.Age = time.Since(info.ModTime())
+		}
+
+		return lockErr
+	case err != nil:
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil && sync {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(lock, path)
+	}
+	if err != nil {
+		os.Remove(lock)
+		return err
+	}
+
+	if sync {
+		syncDir(filepath.Dir(path))
+	}
+
+	return nil
+}
+
+// syncDir flushes the directory entries of dir, making a rename into it durable. Errors are
+// ignored: not every platform can open and sync a directory.
+func syncDir(dir string) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	f.Sync()
+}
+
+func (d *dirFS) Remove(name string) error {
+	path, err := d.path("remove", name)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirFSWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	fsys := DirFS(dir)
+
+	for _, sync := range []bool{false, true} {
+		write := fsys.WriteFile
+		if sync {
+			write = fsys.WriteFileSync
+		}
+
+		if err := write("file", []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := readFile(t, filepath.Join(dir, "file")); got != "content" {
+			t.Errorf("file = %q; want %q", got, "content")
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "file.lock")); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("file.lock was left behind: %v", err)
+		}
+	}
+
+	if err := fsys.WriteFile("../file", nil, 0644); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("WriteFile(../file) = %v; want fs.ErrInvalid", err)
+	}
+}
+
+func TestDirFSWriteFileLocked(t *testing.T) {
+	dir := t.TempDir()
+	fsys := DirFS(dir)
+	writeFile(t, filepath.Join(dir, "file"), "old")
+	writeFile(t, filepath.Join(dir, "file.lock"), "")
+
+	err := fsys.WriteFile("file", []byte("new"), 0644)
+
+	var lockErr *LockedError
+	switch {
+	case !errors.As(err, &lockErr):
+		t.Fatalf("WriteFile = %v; want a LockedError", err)
+	case !errors.Is(err, fs.ErrExist):
+		t.Errorf("errors.Is(%v, fs.ErrExist) = false", err)
+	case lockErr.Path != filepath.Join(dir, "file.lock"):
+		t.Errorf("Path = %q; want the lock file", lockErr.Path)
+	case lockErr.Stale():
+		t.Errorf("Stale() = true for a lock %v old", lockErr.Age)
+	case strings.Contains(err.Error(), "crashed: remove"):
+		t.Errorf("Error() = %q; want it not to call the lock stale", err)
+	}
+
+	if got := readFile(t, filepath.Join(dir, "file")); got != "old" {
+		t.Errorf("file = %q; want it untouched", got)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "file.lock")); err != nil {
+		t.Errorf("the held lock was removed: %v", err)
+	}
+}
+
+func TestDirFSWriteFileStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	lock := filepath.Join(dir, "file.lock")
+	writeFile(t, lock, "")
+
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	var lockErr *LockedError
+	if err := DirFS(dir).WriteFile("file", nil, 0644); !errors.As(err, &lockErr) {
+		t.Fatalf("WriteFile = %v; want a LockedError", err)
+	}
+
+	if !lockErr.Stale() || lockErr.Age < time.Hour {
+		t.Errorf("Stale() = %v with Age %v; want a stale lock at least an hour old", lockErr.Stale(), lockErr.Age)
+	}
+
+	if !strings.Contains(lockErr.Error(), "1h0m0s old") {
+		t.Errorf("Error() = %q; want it to give the age of the lock", lockErr.Error())
+	}
+}
+
+func TestDirFSWriteFileFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	// Renaming the lock file over a non-empty directory fails.
+	writeFile(t, filepath.Join(dir, "file", "child"), "")
+
+	if err := DirFS(dir).WriteFile("file", []byte("content"), 0644); err == nil {
+		t.Fatal("WriteFile over a directory succeeded")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "file.lock")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("file.lock was left behind after a failed write: %v", err)
+	}
+}
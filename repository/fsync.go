@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// fsyncComponents expands the values accepted by "core.fsync" into the components they cover.
+var fsyncComponents = map[string][]string{
+	"loose-object":     {"loose-object"},
+	"pack":             {"pack"},
+	"pack-metadata":    {"pack-metadata"},
+	"commit-graph":     {"commit-graph"},
+	"index":            {"index"},
+	"reference":        {"reference"},
+	"objects":          {"loose-object", "pack"},
+	"derived-metadata": {"pack-metadata", "commit-graph"},
+	"committed":        {"loose-object", "pack", "reference"},
+	"added":            {"loose-object", "pack", "reference", "index"},
+	"all":              {"loose-object", "pack", "pack-metadata", "commit-graph", "index", "reference"},
+}
+
+// fsyncComponent returns the "core.fsync" component the file name, relative to the git
+// directory, belongs to, or an empty string for files that are never flushed, like "config".
+func fsyncComponent(name string) string {
+	dir, file := path.Split(name)
+	switch {
+	case name == "index":
+		return "index"
+	case name == "HEAD", name == "packed-refs", strings.HasPrefix(name, "refs/"):
+		return "reference"
+	case dir == "objects/pack/" && path.Ext(file) == ".pack":
+		return "pack"
+	case dir == "objects/pack/":
+		return "pack-metadata"
+	case strings.HasPrefix(name, "objects/info/commit-graph"):
+		return "commit-graph"
+	case len(dir) == len("objects/xx/") && strings.HasPrefix(dir, "objects/") && dir != "objects/info/":
+		return "loose-object"
+	default:
+		return ""
+	}
+}
+
+// fsyncDefault is the set of components flushed when "core.fsync" doesn't say otherwise.
+var fsyncDefault = []string{"pack", "pack-metadata", "commit-graph"}
+
+// shouldFsync reports whether writes of the file name must reach stable storage before they
+// replace it. Like git, "core.fsync" adds components to the default set of pack files, pack
+// metadata and commit-graphs, "-<component>" removes them, and "none" empties the default set;
+// "core.fsyncObjectFiles" additionally flushes loose objects.
+func (g *GitRepository) shouldFsync(name string) bool {
+	component := fsyncComponent(name)
+	if component == "" {
+		return false
+	}
+
+	if component == "loose-object" && isTrue(g.ConfigValue("core", "fsyncObjectFiles")) {
+		return true
+	}
+
+	// Like git, removals and additions apply after "none", wherever they are listed.
+	current, positive, negative := slices.Contains(fsyncDefault, component), false, false
+	for _, item := range strings.Split(g.ConfigValue("core", "fsync"), ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		switch {
+		case item == "none":
+			current = false
+		case strings.HasPrefix(item, "-"):
+			negative = negative || slices.Contains(fsyncComponents[item[1:]], component)
+		default:
+			positive = positive || slices.Contains(fsyncComponents[item], component)
+		}
+	}
+
+	return current && !negative || positive
+}
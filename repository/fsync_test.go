@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestFsyncComponent(t *testing.T) {
+	tests := map[string]string{
+		"index":                              "index",
+		"HEAD":                               "reference",
+		"packed-refs":                        "reference",
+		"refs/heads/master":                  "reference",
+		"objects/pack/pack-1234.pack":        "pack",
+		"objects/pack/pack-1234.idx":         "pack-metadata",
+		"objects/pack/pack-1234.rev":         "pack-metadata",
+		"objects/info/commit-graph":          "commit-graph",
+		"objects/info/commit-graphs/a.graph": "commit-graph",
+		"objects/ab/cdef0123":                "loose-object",
+		"objects/info/packs":                 "",
+		"config":                             "",
+		"description":                        "",
+		"ORIG_HEAD":                          "",
+	}
+
+	for name, want := range tests {
+		if got := fsyncComponent(name); got != want {
+			t.Errorf("fsyncComponent(%q) = %q; want %q", name, got, want)
+		}
+	}
+}
+
+func TestShouldFsync(t *testing.T) {
+	tests := []struct {
+		fsync       string
+		objectFiles string
+		name        string
+		want        bool
+	}{
+		{name: "objects/pack/pack-1.pack", want: true},
+		{name: "objects/pack/pack-1.idx", want: true},
+		{name: "objects/info/commit-graph", want: true},
+		{name: "objects/ab/cdef", want: false},
+		{name: "refs/heads/master", want: false},
+		{name: "config", want: false},
+
+		// Components are added to the default set.
+		{fsync: "reference", name: "refs/heads/master", want: true},
+		{fsync: "reference", name: "objects/pack/pack-1.pack", want: true},
+		{fsync: "committed", name: "objects/ab/cdef", want: true},
+		{fsync: "added", name: "index", want: true},
+		{fsync: "all", name: "index", want: true},
+		{fsync: "All, Reference", name: "HEAD", want: true},
+		{fsync: "nope", name: "objects/pack/pack-1.pack", want: true},
+
+		// A leading "-" removes a component, even a default one.
+		{fsync: "-pack", name: "objects/pack/pack-1.pack", want: false},
+		{fsync: "-pack", name: "objects/pack/pack-1.idx", want: true},
+		{fsync: "-derived-metadata", name: "objects/info/commit-graph", want: false},
+		{fsync: "reference,-reference", name: "HEAD", want: true},
+
+		// "none" empties the default set only.
+		{fsync: "none", name: "objects/pack/pack-1.pack", want: false},
+		{fsync: "none,reference", name: "objects/pack/pack-1.pack", want: false},
+		{fsync: "none,reference", name: "HEAD", want: true},
+		{fsync: "reference,none", name: "HEAD", want: true},
+
+		{objectFiles: "true", name: "objects/ab/cdef", want: true},
+		{objectFiles: "true", fsync: "none", name: "objects/ab/cdef", want: true},
+		{objectFiles: "false", name: "objects/ab/cdef", want: false},
+		{objectFiles: "true", name: "config", want: false},
+	}
+
+	for _, tt := range tests {
+		repo := &GitRepository{Config: ini.Empty(), overrides: map[string]string{}}
+		if tt.fsync != "" {
+			repo.overrides[configOverrideKey("core", "fsync")] = tt.fsync
+		}
+		if tt.objectFiles != "" {
+			repo.overrides[configOverrideKey("core", "fsyncObjectFiles")] = tt.objectFiles
+		}
+
+		if got := repo.shouldFsync(tt.name); got != tt.want {
+			t.Errorf("shouldFsync(%q) with core.fsync=%q, core.fsyncObjectFiles=%q = %v; want %v", tt.name, tt.fsync, tt.objectFiles, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package repository
+
+// hideGitDir is a no-op outside Windows, where the leading dot already hides ".git".
+func hideGitDir(path string) error {
+	return nil
+}
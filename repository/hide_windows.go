@@ -0,0 +1,19 @@
+package repository
+
+import "syscall"
+
+// hideGitDir marks the ".git" directory at path as hidden, as Git for Windows does by default
+// (core.hideDotFiles=dotGitOnly), so it stays out of Explorer listings.
+func hideGitDir(path string) error {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+
+	attrs, err := syscall.GetFileAttributes(name)
+	if err != nil {
+		return err
+	}
+
+	return syscall.SetFileAttributes(name, attrs|syscall.FILE_ATTRIBUTE_HIDDEN)
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ident identifies the author or committer of a commit.
+type Ident struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// String formats the identity the way it is recorded in commit objects, such as
+// "A U Thor <author@example.com> 1112911993 -0700".
+func (i Ident) String() string {
+	return fmt.Sprintf("%s <%s> %d %s", i.Name, i.Email, i.When.Unix(), i.When.Format("-0700"))
+}
+
+// AuthorIdent returns who is recorded as the author of new commits. Like git, the name and email
+// come from GIT_AUTHOR_NAME and GIT_AUTHOR_EMAIL, then "author.name" and "author.email", then
+// "user.name" and "user.email", and finally from the system; the date comes from GIT_AUTHOR_DATE
+// or is the current time.
+func (g *GitRepository) AuthorIdent() (Ident, error) {
+	return g.ident("author")
+}
+
+// CommitterIdent is like [GitRepository.AuthorIdent] but uses the GIT_COMMITTER_* variables and
+// the "committer" section.
+func (g *GitRepository) CommitterIdent() (Ident, error) {
+	return g.ident("committer")
+}
+
+// ident resolves the identity for role, either "author" or "committer".
+func (g *GitRepository) ident(role string) (Ident, error) {
+	env := "GIT_" + strings.ToUpper(role) + "_"
+	configOnly := isTrue(g.ConfigValue("user", "useConfigOnly"))
+
+	// Like git, "user.useConfigOnly" rejects an email taken from EMAIL as much as a made up one.
+	email, explicit := g.identValue(env+"EMAIL", role, "email")
+	if email == "" && configOnly {
+		return Ident{}, &InvalidIdentError{Reason: "no email was given and auto-detection is disabled"}
+	}
+	if email == "" {
+		email, explicit = os.Getenv("EMAIL"), true
+	}
+	if email == "" {
+		email, explicit = defaultEmail(), false
+	}
+
+	// Like git, an email made up from a host name without a domain is never good enough.
+	if !explicit && strings.HasSuffix(email, ".(none)") {
+		return Ident{}, &InvalidIdentError{Reason: "unable to auto-detect email address (got '" + email + "')"}
+	}
+
+	name, _ := g.identValue(env+"NAME", role, "name")
+	if name == "" {
+		if configOnly {
+			return Ident{}, &InvalidIdentError{Reason: "no name was given and auto-detection is disabled"}
+		}
+
+		name = defaultName()
+	}
+
+	name, email = trimIdent(name), trimIdent(email)
+	if name == "" {
+		return Ident{}, &InvalidIdentError{Reason: "empty ident name (for <" + email + ">) not allowed"}
+	}
+
+	when := time.Now()
+	if date := os.Getenv(env + "DATE"); date != "" {
+		var err error
+		if when, err = parseIdentDate(date); err != nil {
+			return Ident{}, err
+		}
+	}
+
+	return Ident{Name: name, Email: email, When: when}, nil
+}
+
+// identValue returns the first value set among the environment variable, "<role>.<key>" and
+// "user.<key>", and whether one was found.
+func (g *GitRepository) identValue(env, role, key string) (string, bool) {
+	if value := os.Getenv(env); value != "" {
+		return value, true
+	}
+
+	for _, section := range []string{role, "user"} {
+		if value := g.ConfigValue(section, key); value != "" {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// defaultName returns the full name of the current user, or their login name if it is unknown.
+func defaultName() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+
+	// The full name is the first field of the GECOS entry.
+	if name, _, _ := strings.Cut(u.Name, ","); name != "" {
+		return name
+	}
+
+	return u.Username
+}
+
+// defaultEmail makes up an email address from the login and host names. Like git, a host name
+// without a domain gets a ".(none)" suffix, which [GitRepository.ident] refuses.
+func defaultEmail() string {
+	login := "unknown"
+	if u, err := user.Current(); err == nil {
+		login = u.Username
+	}
+
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "(none)"
+	}
+	if !strings.Contains(host, ".") {
+		host += ".(none)"
+	}
+
+	return login + "@" + host
+}
+
+// trimIdent removes the characters git considers crud from both ends of a name or email, and the
+// angle brackets and newlines that would break the ident line anywhere.
+func trimIdent(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '<' || r == '>' || r == '\n' {
+			return -1
+		}
+
+		return r
+	}, s)
+
+	return strings.TrimFunc(s, func(r rune) bool {
+		return r <= ' ' || strings.ContainsRune(".,:;\"\\'", r)
+	})
+}
+
+// identDateLayouts are the formats accepted in GIT_AUTHOR_DATE and GIT_COMMITTER_DATE, besides
+// git's internal "<unix timestamp> <zone>" one.
+var identDateLayouts = []string{
+	time.RFC1123Z,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// parseIdentDate parses a date given through GIT_AUTHOR_DATE or GIT_COMMITTER_DATE. Dates without
+// a zone are taken as local time.
+func parseIdentDate(date string) (time.Time, error) {
+	stamp, zone, _ := strings.Cut(strings.TrimPrefix(date, "@"), " ")
+	if sec, err := strconv.ParseInt(stamp, 10, 64); err == nil {
+		when := time.Unix(sec, 0)
+		if zone == "" {
+			return when, nil
+		}
+
+		if z, err := time.Parse("-0700", zone); err == nil {
+			return when.In(z.Location()), nil
+		}
+	}
+
+	for _, layout := range identDateLayouts {
+		if when, err := time.ParseInLocation(layout, date, time.Local); err == nil {
+			return when, nil
+		}
+	}
+
+	return time.Time{}, &InvalidDateError{Date: date}
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdent(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		config  [][2]string
+		want    string // want is the identity without its date.
+		wantErr bool
+	}{
+		{
+			name:   "user",
+			config: [][2]string{{"user.name", "User"}, {"user.email", "user@example.com"}},
+			want:   "User <user@example.com>",
+		},
+		{
+			name:   "role over user",
+			config: [][2]string{{"user.name", "User"}, {"user.email", "user@example.com"}, {"author.name", "Author"}, {"author.email", "author@example.com"}},
+			want:   "Author <author@example.com>",
+		},
+		{
+			name:   "environment over role",
+			env:    map[string]string{"GIT_AUTHOR_NAME": "Env", "GIT_AUTHOR_EMAIL": "env@example.com"},
+			config: [][2]string{{"author.name", "Author"}, {"author.email", "author@example.com"}},
+			want:   "Env <env@example.com>",
+		},
+		{
+			name:   "other role ignored",
+			env:    map[string]string{"GIT_COMMITTER_NAME": "Committer", "GIT_COMMITTER_EMAIL": "committer@example.com"},
+			config: [][2]string{{"user.name", "User"}, {"committer.email", "committer@example.com"}, {"user.email", "user@example.com"}},
+			want:   "User <user@example.com>",
+		},
+		{
+			name:   "EMAIL after user",
+			env:    map[string]string{"EMAIL": "email@example.com"},
+			config: [][2]string{{"user.name", "User"}},
+			want:   "User <email@example.com>",
+		},
+		{
+			name:   "user over EMAIL",
+			env:    map[string]string{"EMAIL": "email@example.com"},
+			config: [][2]string{{"user.name", "User"}, {"user.email", "user@example.com"}},
+			want:   "User <user@example.com>",
+		},
+		{
+			name:   "crud trimmed",
+			config: [][2]string{{"user.name", " .A <U> Thor;"}, {"user.email", "<author@example.com>"}},
+			want:   "A U Thor <author@example.com>",
+		},
+		{
+			name:   "explicit .(none) accepted",
+			config: [][2]string{{"user.name", "User"}, {"user.email", "user@host.(none)"}},
+			want:   "User <user@host.(none)>",
+		},
+		{
+			name:    "useConfigOnly without email",
+			env:     map[string]string{"EMAIL": "email@example.com"},
+			config:  [][2]string{{"user.useConfigOnly", "true"}, {"user.name", "User"}},
+			wantErr: true,
+		},
+		{
+			name:    "useConfigOnly without name",
+			config:  [][2]string{{"user.useConfigOnly", "yes"}, {"user.email", "user@example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty name",
+			config:  [][2]string{{"user.name", "..."}, {"user.email", "user@example.com"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_AUTHOR_DATE", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL", "EMAIL"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			opts := []Option{}
+			for _, kv := range tt.config {
+				opts = append(opts, WithConfig(kv[0], kv[1]))
+			}
+
+			repo, err := OpenGlobal(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ident, err := repo.AuthorIdent()
+			switch {
+			case tt.wantErr:
+				var identErr *InvalidIdentError
+				if !errors.As(err, &identErr) {
+					t.Fatalf("AuthorIdent() = %v, %v; want an InvalidIdentError", ident, err)
+				}
+			case err != nil:
+				t.Fatalf("AuthorIdent() failed: %v", err)
+			case ident.Name+" <"+ident.Email+">" != tt.want:
+				t.Errorf("AuthorIdent() = %q <%q>; want %q", ident.Name, ident.Email, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentMadeUpEmail(t *testing.T) {
+	if host, err := os.Hostname(); err != nil || strings.Contains(host, ".") {
+		t.Skip("the host name has a domain")
+	}
+
+	t.Setenv("GIT_AUTHOR_EMAIL", "")
+	t.Setenv("EMAIL", "")
+
+	repo, err := OpenGlobal(WithConfig("user.name", "User"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var identErr *InvalidIdentError
+	if ident, err := repo.AuthorIdent(); !errors.As(err, &identErr) || !strings.Contains(err.Error(), ".(none)") {
+		t.Errorf("AuthorIdent() = %v, %v; want the made up email to be rejected", ident, err)
+	}
+}
+
+func TestIdentDate(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_DATE", "@1112911993 -0700")
+
+	repo, err := OpenGlobal(WithConfig("user.name", "A U Thor"), WithConfig("user.email", "author@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ident, err := repo.AuthorIdent()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := ident.String(), "A U Thor <author@example.com> 1112911993 -0700"; got != want {
+		t.Errorf("AuthorIdent() = %q; want %q", got, want)
+	}
+
+	t.Setenv("GIT_AUTHOR_DATE", "yesterday")
+	var dateErr *InvalidDateError
+	if _, err := repo.AuthorIdent(); !errors.As(err, &dateErr) {
+		t.Errorf("AuthorIdent() with an invalid date = %v; want an InvalidDateError", err)
+	}
+}
+
+func TestParseIdentDate(t *testing.T) {
+	tests := []struct {
+		date       string
+		wantUnix   int64
+		wantOffset string // wantOffset is the zone of the parsed date, or empty when it is local.
+		wantErr    bool
+	}{
+		{date: "1112911993 -0700", wantUnix: 1112911993, wantOffset: "-0700"},
+		{date: "@1112911993 +0200", wantUnix: 1112911993, wantOffset: "+0200"},
+		{date: "1112911993", wantUnix: 1112911993},
+		{date: "Thu, 07 Apr 2005 22:13:13 +0200", wantUnix: 1112904793, wantOffset: "+0200"},
+		{date: "Thu, 7 Apr 2005 15:13:13 -0700", wantUnix: 1112911993, wantOffset: "-0700"},
+		{date: "2005-04-07T22:13:13+02:00", wantUnix: 1112904793, wantOffset: "+0200"},
+		{date: "2005-04-07T15:13:13-0700", wantUnix: 1112911993, wantOffset: "-0700"},
+		{date: "2005-04-07 15:13:13 -0700", wantUnix: 1112911993, wantOffset: "-0700"},
+		{date: "2005-04-07 15:13:13", wantUnix: time.Date(2005, 4, 7, 15, 13, 13, 0, time.Local).Unix()},
+		{date: "", wantErr: true},
+		{date: "yesterday", wantErr: true},
+		{date: "1112911993 PDT", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		when, err := parseIdentDate(tt.date)
+		switch {
+		case tt.wantErr:
+			var dateErr *InvalidDateError
+			if !errors.As(err, &dateErr) {
+				t.Errorf("parseIdentDate(%q) = %v, %v; want an InvalidDateError", tt.date, when, err)
+			}
+		case err != nil:
+			t.Errorf("parseIdentDate(%q) failed: %v", tt.date, err)
+		case when.Unix() != tt.wantUnix:
+			t.Errorf("parseIdentDate(%q) = %d; want %d", tt.date, when.Unix(), tt.wantUnix)
+		case tt.wantOffset != "" && when.Format("-0700") != tt.wantOffset:
+			t.Errorf("parseIdentDate(%q) is in zone %s; want %s", tt.date, when.Format("-0700"), tt.wantOffset)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/heiytor/snap/refs"
+	"gopkg.in/ini.v1"
+)
+
+// Init initializes a new git repository and returns it. It creates the path if it does not
+// exists. With [WithBare] the path itself becomes the git directory.
+//
+// Like git, running Init on an existing repository reinitializes it: missing directories and
+// configuration keys are added, but HEAD and the existing configuration are left alone, and
+// [WithDefaultBranch] is ignored. Asking for a different object format with [WithObjectFormat]
+// fails with an [ObjectFormatMismatchError]. See [GitRepository.Reinitialized].
+func Init(path string, opts ...Option) (*GitRepository, error) {
+	o := newOptions(opts)
+
+	overrides, err := parseConfigOverrides(o.config)
+	if err != nil {
+		return nil, err
+	}
+
+	globalConfig, err := loadGlobalConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	path, _ = filepath.Abs(path)
+	repo := &GitRepository{WorkTree: path, GitDir: filepath.Join(path, ".git"), Config: ini.Empty(), overrides: overrides, globalConfig: globalConfig}
+	if o.bare {
+		repo.WorkTree, repo.GitDir = "", path
+	}
+	repo.FS = DirFS(repo.GitDir)
+
+	if repo.HasFile([]string{"config"}) {
+		if err := repo.loadConfig(); err != nil {
+			return nil, err
+		}
+
+		if o.objectFormat != "" && o.objectFormat != repo.ObjectFormat() {
+			return nil, &ObjectFormatMismatchError{Existing: repo.ObjectFormat(), Requested: o.objectFormat}
+		}
+
+		repo.reinitialized = true
+	}
+
+	format := o.objectFormat
+	if format == "" {
+		format = repo.ObjectFormat()
+	}
+	if format != "sha1" && format != "sha256" {
+		return nil, &UnknownObjectFormatError{Format: format}
+	}
+
+	head := ""
+	if !repo.HasFile([]string{"HEAD"}) {
+		branch := o.defaultBranch
+		if branch == "" {
+			branch = repo.ConfigValue("init", "defaultBranch")
+		}
+		if branch == "" {
+			branch = "master"
+		}
+		if !refs.ValidName("refs/heads/" + branch) {
+			return nil, &InvalidBranchNameError{Name: branch}
+		}
+
+		head = "ref: refs/heads/" + branch + "\n"
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.MkdirAll(path, 0777); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := repo.HasOrMkDirs([]string{"branches"}, []string{"objects"}, []string{"refs", "tags"}, []string{"refs", "heads"}); err != nil {
+		return nil, err
+	}
+
+	if !o.bare && !isFalse(repo.ConfigValue("core", "hideDotFiles")) {
+		if err := hideGitDir(repo.GitDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if !repo.HasFile([]string{"description"}) {
+		if err := repo.WriteFile("description", "Unnamed repository; edit this file 'description' to name the repository.\n"); err != nil {
+			return nil, err
+		}
+	}
+
+	if head != "" {
+		if err := repo.WriteFile("HEAD", head); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(o.alternates) > 0 {
+		if _, err := repo.HasOrMkDir("objects", "info"); err != nil {
+			return nil, err
+		}
+
+		if err := repo.WriteFile("objects/info/alternates", strings.Join(o.alternates, "\n")+"\n"); err != nil {
+			return nil, err
+		}
+	}
+
+	// sha256 repositories need the "extensions" section, which only exists from version 1.
+	if format == "sha1" {
+		repo.setConfigDefault("core", "repositoryformatversion", "0")
+	} else {
+		repo.setConfigDefault("core", "repositoryformatversion", "1")
+		repo.setConfigDefault("extensions", "objectformat", format)
+	}
+	repo.setConfigDefault("core", "filemode", "false") // Disable permissions track
+	repo.setConfigDefault("core", "bare", strconv.FormatBool(o.bare))
+	if err := repo.SaveConfig(); err != nil {
+		return nil, err
+	}
+
+	// Like git, only record core.ignorecase when the filesystem actually folds case.
+	if repo.IsCaseInsensitive() {
+		repo.setConfigDefault("core", "ignorecase", "true")
+	}
+
+	if err := repo.probePrecomposeUnicode(); err != nil {
+		return nil, err
+	}
+
+	if err := repo.SaveConfig(); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// Reinitialized reports whether [Init] found an existing repository rather than creating one.
+func (g *GitRepository) Reinitialized() bool {
+	return g.reinitialized
+}
+
+// setConfigDefault is like [GitRepository.SetConfig] but leaves a key already present in the
+// "config" file alone.
+func (g *GitRepository) setConfigDefault(section, key, value string) {
+	g.configMu.Lock()
+	defer g.configMu.Unlock()
+
+	if findConfigKey(g.Config, section, key) == nil {
+		g.Config.Section(section).Key(key).SetValue(value)
+	}
+}
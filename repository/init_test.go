@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readFile returns the content of name, failing the test if it can't be read.
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content)
+}
+
+func TestInit(t *testing.T) {
+	dir := t.TempDir()
+
+	repo, err := Init(dir, WithObjectFormat("sha256"), WithDefaultBranch("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if repo.Reinitialized() {
+		t.Error("Reinitialized() = true for a new repository")
+	}
+
+	if head := readFile(t, filepath.Join(dir, ".git", "HEAD")); head != "ref: refs/heads/main\n" {
+		t.Errorf("HEAD = %q; want refs/heads/main", head)
+	}
+
+	for key, want := range map[[2]string]string{
+		{"core", "repositoryformatversion"}: "1",
+		{"core", "bare"}:                    "false",
+		{"extensions", "objectformat"}:      "sha256",
+	} {
+		if got := repo.ConfigValue(key[0], key[1]); got != want {
+			t.Errorf("%s.%s = %q; want %q", key[0], key[1], got, want)
+		}
+	}
+}
+
+func TestInitInvalidBranch(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "repo")
+
+	_, err := Init(dir, WithDefaultBranch("a..b c"))
+	var invalid *InvalidBranchNameError
+	if !errors.As(err, &invalid) || invalid.Name != "a..b c" {
+		t.Fatalf("Init = %v; want an InvalidBranchNameError", err)
+	}
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Init created %s for an invalid branch: %v", dir, err)
+	}
+
+	if _, err := Init(dir, WithConfig("init.defaultBranch", "x.lock")); !errors.As(err, &invalid) {
+		t.Fatalf("Init = %v; want an InvalidBranchNameError for init.defaultBranch", err)
+	}
+}
+
+func TestReinit(t *testing.T) {
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+
+	if _, err := Init(dir, WithObjectFormat("sha256"), WithDefaultBranch("main")); err != nil {
+		t.Fatal(err)
+	}
+
+	config := readFile(t, filepath.Join(gitDir, "config"))
+	writeFile(t, filepath.Join(gitDir, "config"), config+"[remote \"origin\"]\n\turl = https://example.com/repo.git\n[core]\n\tfilemode = true\n")
+	writeFile(t, filepath.Join(gitDir, "description"), "mine\n")
+
+	// The branch is ignored, invalid or not, since HEAD already exists.
+	repo, err := Init(dir, WithDefaultBranch("a..b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !repo.Reinitialized() {
+		t.Error("Reinitialized() = false for an existing repository")
+	}
+
+	if head := readFile(t, filepath.Join(gitDir, "HEAD")); head != "ref: refs/heads/main\n" {
+		t.Errorf("HEAD = %q; want it left at refs/heads/main", head)
+	}
+
+	if description := readFile(t, filepath.Join(gitDir, "description")); description != "mine\n" {
+		t.Errorf("description = %q; want it left alone", description)
+	}
+
+	repo, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[[2]string]string{
+		{"core", "repositoryformatversion"}: "1",
+		{"core", "filemode"}:                "true",
+		{"extensions", "objectformat"}:      "sha256",
+		{`remote "origin"`, "url"}:          "https://example.com/repo.git",
+	} {
+		if got := repo.ConfigValue(key[0], key[1]); got != want {
+			t.Errorf("%s.%s = %q; want %q", key[0], key[1], got, want)
+		}
+	}
+
+	_, err = Init(dir, WithObjectFormat("sha1"))
+	var mismatch *ObjectFormatMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Existing != "sha256" || mismatch.Requested != "sha1" {
+		t.Fatalf("Init = %v; want an ObjectFormatMismatchError", err)
+	}
+
+	if _, err := Init(dir, WithObjectFormat("sha256")); err != nil {
+		t.Fatalf("Init with the same object format failed: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import "io/fs"
+
+// Option configures how [Open] and [Init] access a repository.
+type Option func(*options)
+
+type options struct {
+	bare          bool
+	fs            fs.FS
+	objectFormat  string
+	defaultBranch string
+	alternates    []string
+	config        [][2]string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithBare treats the path given to [Open] or [Init] as the git directory itself, with no
+// work tree.
+func WithBare() Option {
+	return func(o *options) {
+		o.bare = true
+	}
+}
+
+// WithFS makes [Open] read the git directory from fsys instead of the operating system. No
+// discovery is performed and the path is only recorded as [GitRepository.GitDir]. If fsys does
+// not implement [WriteFS] the repository is read-only.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fs = fsys
+	}
+}
+
+// WithObjectFormat sets the hash algorithm, "sha1" or "sha256", used by a repository created
+// with [Init]. Defaults to "sha1", or to the format of the repository being reinitialized.
+func WithObjectFormat(format string) Option {
+	return func(o *options) {
+		o.objectFormat = format
+	}
+}
+
+// WithDefaultBranch sets the branch HEAD points to in a repository created with [Init]. Defaults
+// to "init.defaultBranch" as set through [WithConfig], or "master". The name must be a valid
+// branch name, or [Init] fails with an [InvalidBranchNameError].
+func WithDefaultBranch(name string) Option {
+	return func(o *options) {
+		o.defaultBranch = name
+	}
+}
+
+// WithAlternates lists object directories, written to "objects/info/alternates" by [Init], that
+// the repository borrows objects from.
+func WithAlternates(dirs ...string) Option {
+	return func(o *options) {
+		o.alternates = append(o.alternates, dirs...)
+	}
+}
+
+// WithConfig overrides the configuration key, in "section[.subsection].name" form, with value for
+// the lifetime of the returned repository, like "git -c key=value". Overrides are only visible
+// through [GitRepository.ConfigValue] and are never written to the "config" file.
+func WithConfig(key, value string) Option {
+	return func(o *options) {
+		o.config = append(o.config, [2]string{key, value})
+	}
+}
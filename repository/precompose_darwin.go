@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"io/fs"
+	"strconv"
+)
+
+// probePrecomposeUnicode sets "core.precomposeunicode", as git does on macOS, by checking whether
+// a file created with a precomposed UTF-8 name can also be found by its decomposed form, which is
+// what readdir returns on HFS+ and APFS.
+func (g *GitRepository) probePrecomposeUnicode() error {
+	const precomposed, decomposed = "tmp_precomp_\u00c4", "tmp_precomp_A\u0308"
+
+	fsys, err := g.writable()
+	if err != nil {
+		return err
+	}
+
+	if err := fsys.WriteFile(precomposed, nil, 0644); err != nil {
+		return err
+	}
+	defer fsys.Remove(precomposed)
+
+	_, err = fs.Stat(g.FS, decomposed)
+	g.setConfigDefault("core", "precomposeunicode", strconv.FormatBool(err == nil))
+
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package repository
+
+// probePrecomposeUnicode is a no-op outside macOS, where filesystems don't decompose names.
+func (g *GitRepository) probePrecomposeUnicode() error {
+	return nil
+}
@@ -0,0 +1,407 @@
+// Package repository implements access to git repositories on disk: discovering the
+// ".git" directory, reading its configuration and creating new repositories.
+package repository
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/ini.v1"
+)
+
+// GitRepository represents the ".git" directory. Use [Open] or [Init] to create one.
+//
+// A GitRepository may be shared by concurrent goroutines as long as its fields are not reassigned
+// and the configuration is accessed through [GitRepository.ConfigValue], [GitRepository.SetConfig]
+// and [GitRepository.SaveConfig] rather than through [GitRepository.Config] directly.
+type GitRepository struct {
+	WorkTree string    // WorkTree is the root directory where [GitRepository.GitDir] is located. Empty for bare repositories.
+	GitDir   string    // GitDir is the ".git" directory under [GitRepository.WorkTree].
+	Config   *ini.File // Config holds the parsed contents of the ".git/config" file.
+	FS       fs.FS     // FS gives access to the contents of [GitRepository.GitDir]; see [WriteFS].
+
+	overrides     map[string]string // overrides holds the values given through [WithConfig]; see [configOverrideKey].
+	globalConfig  []*ini.File       // globalConfig holds the system and global configuration; see [loadGlobalConfig].
+	reinitialized bool              // reinitialized is set by [Init] when the repository already existed.
+
+	configMu sync.RWMutex // configMu guards the keys of [GitRepository.Config].
+	saveMu   sync.Mutex   // saveMu serializes writes of the "config" file.
+}
+
+// findGitDirectory searches for a ".git" directory starting from the provided startDir
+// and traversing up to the root directory ("/", or the volume root such as "C:\" on Windows).
+// A ".git" file is followed to the directory it points to (see [readGitFile]). It returns the
+// directory where ".git" was found together with the git directory. Like git, a directory that
+// is itself a git directory, such as a bare repository, is found too, with an empty work tree.
+// If no directory is found, it returns a [RepositoryNotFoundError].
+func findGitDirectory(startDir string) (string, string, error) {
+	dir := startDir
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if file, err := os.Stat(gitDir); err == nil {
+			if !file.IsDir() {
+				var err error
+				if gitDir, err = readGitFile(gitDir); err != nil {
+					return "", "", err
+				}
+			}
+
+			return dir, gitDir, nil
+		}
+
+		if isGitDirectory(dir) {
+			return "", dir, nil
+		}
+
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			break
+		}
+
+		dir = parentDir
+	}
+
+	return "", "", &RepositoryNotFoundError{Path: startDir}
+}
+
+// isGitDirectory reports whether dir looks like a git directory: it has a HEAD file and the
+// "objects" and "refs" directories.
+func isGitDirectory(dir string) bool {
+	if file, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || file.IsDir() {
+		return false
+	}
+
+	for _, name := range []string{"objects", "refs"} {
+		if file, err := os.Stat(filepath.Join(dir, name)); err != nil || !file.IsDir() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readGitFile reads a ".git" file, as used by submodules and linked worktrees, and returns
+// the directory named by its "gitdir: <path>" line. Relative paths are resolved against
+// the directory containing the file.
+func readGitFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	line, _, _ := strings.Cut(string(content), "\n")
+	gitDir, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), "gitdir: ")
+	if !ok || gitDir == "" {
+		return "", &InvalidGitFileError{Path: path}
+	}
+
+	gitDir = filepath.FromSlash(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+
+	file, err := os.Stat(gitDir)
+	switch {
+	case os.IsNotExist(err):
+		return "", &NotExistError{Path: gitDir}
+	case err != nil:
+		return "", err
+	case !file.IsDir():
+		return "", &NotDirectoryError{Path: gitDir}
+	default:
+		return gitDir, nil
+	}
+}
+
+// Open opens an existing [GitRepository]. Unless [WithBare] or [WithFS] are given, the git
+// directory is searched from path upwards (see [findGitDirectory]). It fails if there's no "config" file or if the
+// repository format is not supported (see [GitRepository.ObjectFormat]).
+func Open(path string, opts ...Option) (*GitRepository, error) {
+	o := newOptions(opts)
+
+	overrides, err := parseConfigOverrides(o.config)
+	if err != nil {
+		return nil, err
+	}
+
+	globalConfig, err := loadGlobalConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &GitRepository{GitDir: path, FS: o.fs, overrides: overrides, globalConfig: globalConfig}
+	switch {
+	case o.fs != nil:
+	case o.bare:
+		repo.GitDir, _ = filepath.Abs(path)
+		repo.FS = DirFS(repo.GitDir)
+	default:
+		path, _ = filepath.Abs(path)
+
+		workTree, gitDir, err := findGitDirectory(path)
+		if err != nil {
+			return nil, err
+		}
+
+		repo.WorkTree, repo.GitDir, repo.FS = workTree, gitDir, DirFS(gitDir)
+	}
+
+	if err := repo.loadConfig(); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// OpenGlobal returns a [GitRepository] for commands that can run outside of a repository. It has
+// no git directory nor [GitRepository.FS], and its configuration only comes from [WithConfig] and
+// the global and system configuration files.
+func OpenGlobal(opts ...Option) (*GitRepository, error) {
+	o := newOptions(opts)
+
+	overrides, err := parseConfigOverrides(o.config)
+	if err != nil {
+		return nil, err
+	}
+
+	globalConfig, err := loadGlobalConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitRepository{Config: ini.Empty(), overrides: overrides, globalConfig: globalConfig}, nil
+}
+
+// loadConfig parses the "config" file into [GitRepository.Config] and validates the repository
+// format it declares.
+func (g *GitRepository) loadConfig() error {
+	if !g.HasFile([]string{"config"}) {
+		return ErrMissingConfiguration
+	}
+
+	content, err := fs.ReadFile(g.FS, "config")
+	if err != nil {
+		return err
+	}
+
+	g.Config, err = ini.Load(content)
+	if err != nil {
+		return err
+	}
+
+	version := 0
+	if value, ok := g.lookupRepositoryConfig("core", "repositoryformatversion"); ok {
+		if version, err = strconv.Atoi(value); err != nil {
+			return &BadConfigValueError{Key: "core.repositoryformatversion", Value: value}
+		}
+	}
+
+	switch version {
+	case 0:
+	case 1:
+		if format := g.ObjectFormat(); format != "sha1" && format != "sha256" {
+			return &UnsupportedFormatError{Version: version, Extension: "objectformat=" + format}
+		}
+	default:
+		return &UnsupportedFormatError{Version: version}
+	}
+
+	return nil
+}
+
+// ObjectFormat returns the hash algorithm used to name objects, either "sha1" or "sha256".
+func (g *GitRepository) ObjectFormat() string {
+	if format, _ := g.lookupRepositoryConfig("extensions", "objectformat"); format != "" {
+		return format
+	}
+
+	return "sha1"
+}
+
+// IsBare reports whether the repository is bare. Like git, "core.bare" decides when it is set,
+// and a repository without a work tree, such as one found from inside its git directory, is bare
+// otherwise.
+func (g *GitRepository) IsBare() bool {
+	bare := g.ConfigValue("core", "bare")
+	return isTrue(bare) || g.WorkTree == "" && !isFalse(bare)
+}
+
+// join joins the given path elements into a name under [GitRepository.FS].
+func (g *GitRepository) join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+// writable returns [GitRepository.FS] as a [WriteFS], or [ErrReadOnly] if it can't be written.
+func (g *GitRepository) writable() (WriteFS, error) {
+	fsys, ok := g.FS.(WriteFS)
+	if !ok {
+		return nil, ErrReadOnly
+	}
+
+	return fsys, nil
+}
+
+// HasFile reports whether the file specified by the filepath exists under [GitRepository.GitDir].
+func (g *GitRepository) HasFile(filepath []string) bool {
+	file, err := fs.Stat(g.FS, g.join(filepath...))
+	return err == nil && !file.IsDir()
+}
+
+// HasDir reports whether the give path is a directory under [GitRepository.GitDir]
+func (g *GitRepository) HasDir(path ...string) bool {
+	file, err := fs.Stat(g.FS, g.join(path...))
+	return err == nil && file.IsDir()
+}
+
+// HasOrMkDir is similar to [GitRepository.HasDir] but creates the directory if it does ot exists. The created
+// dir will have 0777 as permissions.
+func (g *GitRepository) HasOrMkDir(path ...string) (bool, error) {
+	if _, err := g.HasOrMkDirs(path); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// HasOrMkDirs is similar to [GitRepository.HasOrMkDir] but checks a list of paths.
+// It returns true if all paths exist, otherwise returns false along with the index of the first path where the error occurs.
+func (g *GitRepository) HasOrMkDirs(paths ...[]string) (int, error) {
+	for i, p := range paths {
+		if ok := g.HasDir(p...); !ok {
+			fsys, err := g.writable()
+			if err != nil {
+				return i, err
+			}
+
+			if err := fsys.MkdirAll(g.join(p...), 0777); err != nil {
+				return i, err
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+// WriteFile atomically replaces the given file with content. The file will be joined to
+// [GitRepository.GitDir]. It is flushed to stable storage first when "core.fsync" asks for it
+// and [GitRepository.FS] is a [SyncFS]; see [GitRepository.shouldFsync].
+func (g *GitRepository) WriteFile(file, content string) error {
+	fsys, err := g.writable()
+	if err != nil {
+		return err
+	}
+
+	name := g.join(file)
+	if syncFS, ok := fsys.(SyncFS); ok && g.shouldFsync(name) {
+		return syncFS.WriteFileSync(name, []byte(content), 0644)
+	}
+
+	return fsys.WriteFile(name, []byte(content), 0644)
+}
+
+// ConfigValue returns the value of key in section of the configuration, or an empty string if it
+// is not set. Like git, values given through [WithConfig] take precedence over
+// [GitRepository.Config], which takes precedence over the global and system configuration files.
+// Section and key names are case-insensitive while subsections are not, and the last matching
+// entry of a file wins.
+func (g *GitRepository) ConfigValue(section, key string) string {
+	value, _ := g.lookupConfig(section, key)
+	return value
+}
+
+// lookupConfig is like [GitRepository.ConfigValue] but also reports whether the key is set, so an
+// empty value can be told apart from a missing one.
+func (g *GitRepository) lookupConfig(section, key string) (string, bool) {
+	if value, ok := g.lookupRepositoryConfig(section, key); ok {
+		return value, true
+	}
+
+	for i := len(g.globalConfig) - 1; i >= 0; i-- {
+		if k := findConfigKey(g.globalConfig[i], section, key); k != nil {
+			return k.String(), true
+		}
+	}
+
+	return "", false
+}
+
+// lookupRepositoryConfig is like [GitRepository.lookupConfig] but ignores the global and system
+// configuration files, for keys such as "core.repositoryformatversion" that only make sense in
+// the repository.
+func (g *GitRepository) lookupRepositoryConfig(section, key string) (string, bool) {
+	if value, ok := g.overrides[configOverrideKey(section, key)]; ok {
+		return value, true
+	}
+
+	g.configMu.RLock()
+	defer g.configMu.RUnlock()
+
+	if k := findConfigKey(g.Config, section, key); k != nil {
+		return k.String(), true
+	}
+
+	return "", false
+}
+
+// findConfigKey returns the last entry of config for key in section, compared through
+// [configOverrideKey], or nil if there's none. The caller must hold configMu when config is
+// [GitRepository.Config].
+func findConfigKey(config *ini.File, section, key string) *ini.Key {
+	want := configOverrideKey(section, key)
+
+	var found *ini.Key
+	for _, sec := range config.Sections() {
+		for _, k := range sec.Keys() {
+			if configOverrideKey(sec.Name(), k.Name()) == want {
+				found = k
+			}
+		}
+	}
+
+	return found
+}
+
+// SetConfig sets key in section of [GitRepository.Config] to value, updating the existing entry
+// if there's one spelled with a different case. Use [GitRepository.SaveConfig] to persist it.
+func (g *GitRepository) SetConfig(section, key, value string) {
+	g.configMu.Lock()
+	defer g.configMu.Unlock()
+
+	if k := findConfigKey(g.Config, section, key); k != nil {
+		k.SetValue(value)
+		return
+	}
+
+	g.Config.Section(section).Key(key).SetValue(value)
+}
+
+// SaveConfig writes [GitRepository.Config] back to the "config" file.
+func (g *GitRepository) SaveConfig() error {
+	g.saveMu.Lock()
+	defer g.saveMu.Unlock()
+
+	buf := new(bytes.Buffer)
+
+	g.configMu.RLock()
+	_, err := g.Config.WriteTo(buf)
+	g.configMu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return g.WriteFile("config", buf.String())
+}
+
+// IsCaseInsensitive reports whether the filesystem holding [GitRepository.GitDir] treats paths
+// case-insensitively. It probes an existing file under a different case, so it must be called
+// after the "config" file has been written.
+func (g *GitRepository) IsCaseInsensitive() bool {
+	file, err := fs.Stat(g.FS, "CoNfIg")
+	return err == nil && !file.IsDir()
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestMain keeps the system and global configuration of the machine running the tests out of
+// them.
+func TestMain(m *testing.M) {
+	os.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	os.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	os.Exit(m.Run())
+}
+
+// writeFile writes content to name, creating its parent directories.
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadGitFile(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "modules", "sub")
+	if err := os.MkdirAll(target, 0777); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "regular"), "")
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr any // wantErr is a pointer to the expected error type, if any.
+	}{
+		{name: "relative", content: "gitdir: ../modules/sub\n", want: target},
+		{name: "absolute", content: "gitdir: " + target + "\n", want: target},
+		{name: "no newline", content: "gitdir: ../modules/sub", want: target},
+		{name: "crlf", content: "gitdir: ../modules/sub\r\n", want: target},
+		{name: "trailing lines", content: "gitdir: ../modules/sub\nignored\n", want: target},
+		{name: "missing target", content: "gitdir: ../modules/nope\n", wantErr: new(*NotExistError)},
+		{name: "file target", content: "gitdir: ../regular\n", wantErr: new(*NotDirectoryError)},
+		{name: "empty", content: "", wantErr: new(*InvalidGitFileError)},
+		{name: "no path", content: "gitdir: \n", wantErr: new(*InvalidGitFileError)},
+		{name: "no prefix", content: "../modules/sub\n", wantErr: new(*InvalidGitFileError)},
+		{name: "wrong case", content: "GITDIR: ../modules/sub\n", wantErr: new(*InvalidGitFileError)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, "worktree", ".git")
+			writeFile(t, file, tt.content)
+
+			got, err := readGitFile(file)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.As(err, tt.wantErr) {
+					t.Fatalf("readGitFile(%q) = %q, %v; want a %T", tt.content, got, err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("readGitFile(%q) failed: %v", tt.content, err)
+			case got != tt.want:
+				t.Fatalf("readGitFile(%q) = %q; want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing target is not exist", func(t *testing.T) {
+		file := filepath.Join(dir, "worktree", ".git")
+		writeFile(t, file, "gitdir: nope\n")
+
+		if _, err := readGitFile(file); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("readGitFile = %v; want fs.ErrNotExist", err)
+		}
+	})
+}
+
+func TestFindGitDirectoryThroughGitFile(t *testing.T) {
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, "main", ".git", "worktrees", "wt")
+	if err := os.MkdirAll(gitDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	workTree := filepath.Join(dir, "wt")
+	writeFile(t, filepath.Join(workTree, ".git"), "gitdir: ../main/.git/worktrees/wt\n")
+	if err := os.MkdirAll(filepath.Join(workTree, "a", "b"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	gotWorkTree, gotGitDir, err := findGitDirectory(filepath.Join(workTree, "a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotWorkTree != workTree || gotGitDir != gitDir {
+		t.Errorf("findGitDirectory = %q, %q; want %q, %q", gotWorkTree, gotGitDir, workTree, gitDir)
+	}
+}
+
+func TestOpenFormatVersion(t *testing.T) {
+	tests := []struct {
+		config  string
+		wantErr any // wantErr is a pointer to the expected error type, if any.
+	}{
+		{config: ""},
+		{config: "[core]\n\trepositoryformatversion = 0\n"},
+		{config: "[core]\n\trepositoryformatversion = 1\n[extensions]\n\tobjectformat = sha256\n"},
+		{config: "[core]\n\trepositoryformatversion = abc\n", wantErr: new(*BadConfigValueError)},
+		{config: "[core]\n\trepositoryformatversion =\n", wantErr: new(*BadConfigValueError)},
+		{config: "[core]\n\trepositoryformatversion = 2\n", wantErr: new(*UnsupportedFormatError)},
+		{config: "[core]\n\trepositoryformatversion = 1\n[extensions]\n\tobjectformat = md5\n", wantErr: new(*UnsupportedFormatError)},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config"), tt.config)
+
+		_, err := Open(dir, WithBare())
+		switch {
+		case tt.wantErr != nil:
+			if !errors.As(err, tt.wantErr) {
+				t.Errorf("Open with %q = %v; want a %T", tt.config, err, tt.wantErr)
+			}
+		case err != nil:
+			t.Errorf("Open with %q failed: %v", tt.config, err)
+		}
+	}
+}
+
+// TestConfigConcurrency is meant to be run with -race, which enforces that a [GitRepository] may
+// be shared by goroutines that read, set and save its configuration.
+func TestConfigConcurrency(t *testing.T) {
+	repo, err := Init(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			key := "key" + strconv.Itoa(i)
+			for j := range 20 {
+				repo.SetConfig("test", key, strconv.Itoa(j))
+				if got := repo.ConfigValue("test", key); got != strconv.Itoa(j) {
+					t.Errorf("test.%s = %q; want %q", key, got, strconv.Itoa(j))
+				}
+
+				if err := repo.SaveConfig(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	saved, err := Open(repo.GitDir, WithBare())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 8 {
+		if got := saved.ConfigValue("test", "key"+strconv.Itoa(i)); got != "19" {
+			t.Errorf("saved test.key%d = %q; want %q", i, got, "19")
+		}
+	}
+}
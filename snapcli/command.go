@@ -0,0 +1,240 @@
+package snapcli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// env is the environment a command runs in.
+type env struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	dir    string      // dir is the absolute directory relative paths are resolved against; see -C.
+	config [][2]string // config holds the key/value pairs given with -c.
+}
+
+// runFunc runs a command with the positional arguments left after parsing its flags.
+type runFunc func(e *env, args []string) error
+
+// command describes a snap subcommand.
+type command struct {
+	name  string // name is the word used to invoke the command.
+	usage string // usage is the synopsis printed after "snap <name>".
+	short string // short is the one-line description listed by "snap help".
+
+	hidden       bool // hidden commands are not listed by "snap help" nor completed.
+	completeRefs bool // completeRefs makes shell completion offer branch and tag names.
+	inOrder      bool // inOrder passes flags along with the arguments, in order; see [parseArgs].
+
+	// setup defines the command's flags on fs and returns the function that runs it once they
+	// are parsed. It is nil for commands that are not implemented yet.
+	setup func(fs *flag.FlagSet) runFunc
+}
+
+// Exit codes, matching git's.
+const (
+	exitError = 1   // exitError is returned for failures reported with an "error:" prefix.
+	exitFatal = 128 // exitFatal is returned for failures reported with a "fatal:" prefix.
+	exitUsage = 129 // exitUsage is returned when a command is invoked incorrectly or with -h.
+)
+
+// usageError reports a command invoked with invalid arguments. The command usage is printed
+// along with it and snap exits with [exitUsage].
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// nonFatalError is a failure reported as "error: <msg>" with [exitError], rather than the
+// "fatal: <msg>" and [exitFatal] used for any other error returned by a command.
+type nonFatalError struct {
+	msg string
+}
+
+func (e *nonFatalError) Error() string {
+	return e.msg
+}
+
+// commands lists every snap subcommand, in the order they are printed by "snap help".
+var commands = []*command{
+	{name: "add", short: "Add file contents to the index"},
+	{name: "cat-file", short: "Provide contents or details of repository objects", completeRefs: true},
+	{name: "check-ignore", short: "Debug gitignore / exclude files"},
+	{name: "checkout", short: "Switch branches or restore working tree files", completeRefs: true},
+	{name: "commit", short: "Record changes to the repository"},
+	{name: "hash-object", short: "Compute object ID and optionally create an object from a file"},
+	initCommand,
+	{name: "log", short: "Show commit logs", completeRefs: true},
+	{name: "ls-files", short: "Show information about files in the index and the working tree"},
+	{name: "ls-tree", short: "List the contents of a tree object", completeRefs: true},
+	revParseCommand,
+	{name: "rm", short: "Remove files from the working tree and from the index"},
+	{name: "show-ref", short: "List references in a local repository", completeRefs: true},
+	{name: "status", short: "Show the working tree status"},
+	{name: "tag", short: "Create, list, delete or verify a tag object", completeRefs: true},
+	varCommand,
+}
+
+// lookupCommand returns the command called name, or nil if there's none.
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// run parses args according to the command flags and runs it.
+func (c *command) run(e *env, args []string) error {
+	if c.setup == nil {
+		return &nonFatalError{msg: fmt.Sprintf("'%s' is not implemented yet", c.name)}
+	}
+
+	// Errors and usage are printed here rather than by the flag package, so they can be
+	// prefixed and sent to the right stream.
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+
+	run := c.setup(fs)
+
+	positional, err := parseArgs(fs, args, c.inOrder)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		c.printUsage(fs, e.stdout)
+		return err
+	case err != nil:
+		err = &usageError{msg: err.Error()}
+	default:
+		err = run(e, positional)
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintln(e.stderr, "error:", usageErr.msg)
+		c.printUsage(fs, e.stderr)
+	}
+
+	return err
+}
+
+// printUsage writes the command synopsis and its flags to w. Like in the synopsis and in shell
+// completion, multi-letter flags are spelled with two dashes.
+func (c *command) printUsage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintf(w, "usage: snap %s %s\n", c.name, c.usage)
+
+	hasFlags := false
+	fs.VisitAll(func(f *flag.Flag) {
+		if !hasFlags {
+			fmt.Fprintln(w)
+			hasFlags = true
+		}
+
+		fmt.Fprintln(w, flagUsage(f))
+	})
+}
+
+// flagUsage formats the help of f the way [flag.PrintDefaults] does, but with "--" in front of
+// multi-letter names.
+func flagUsage(f *flag.Flag) string {
+	b := new(strings.Builder)
+	if len(f.Name) == 1 {
+		fmt.Fprintf(b, "  -%s", f.Name)
+	} else {
+		fmt.Fprintf(b, "  --%s", f.Name)
+	}
+
+	name, usage := flag.UnquoteUsage(f)
+	if name != "" {
+		b.WriteString(" " + name)
+	}
+
+	// Short flags fit before the tab stop; anything longer gets its own line.
+	if b.Len() <= 4 {
+		b.WriteString("\t")
+	} else {
+		b.WriteString("\n    \t")
+	}
+	b.WriteString(strings.ReplaceAll(usage, "\n", "\n    \t"))
+
+	if getter, ok := f.Value.(flag.Getter); ok {
+		switch value := getter.Get().(type) {
+		case string:
+			if value != "" {
+				fmt.Fprintf(b, " (default %q)", value)
+			}
+		case bool:
+			if value {
+				b.WriteString(" (default true)")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// parseArgs parses the flags in args, which may be interleaved with positional arguments, and
+// returns the positional ones. Everything after a "--" separator is positional. With inOrder, the
+// flags and the separator are returned too, as written and among the positional arguments, so
+// commands such as rev-parse can handle them in the order they were given.
+func parseArgs(fs *flag.FlagSet, args []string, inOrder bool) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		// Parse stops either after consuming a "--" or at the first non-flag argument.
+		rest := fs.Args()
+		consumed := args[:len(args)-len(rest)]
+		if inOrder {
+			positional = append(positional, consumed...)
+		}
+
+		if len(consumed) > 0 && consumed[len(consumed)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// printCommands writes the top-level usage, listing every command, to w.
+func printCommands(w io.Writer) {
+	fmt.Fprintln(w, "usage: snap [-C <path>] [-c <name>=<value>] <command> [<args>]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "These are the available snap commands:")
+
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.name))
+	}
+
+	for _, cmd := range commands {
+		if cmd.hidden {
+			continue
+		}
+
+		fmt.Fprintf(w, "   %s%s   %s\n", cmd.name, strings.Repeat(" ", width-len(cmd.name)), cmd.short)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "See 'snap <command> -h' to read about a specific command.")
+}
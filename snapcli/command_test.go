@@ -0,0 +1,75 @@
+package snapcli
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args       []string
+		inOrder    bool
+		positional []string
+		quiet      bool
+		branch     string
+	}{
+		{args: []string{}, positional: []string{}},
+		{args: []string{"dir"}, positional: []string{"dir"}},
+		{args: []string{"-q", "dir"}, positional: []string{"dir"}, quiet: true},
+		{args: []string{"dir", "-q"}, positional: []string{"dir"}, quiet: true},
+		{args: []string{"a", "-b", "main", "b"}, positional: []string{"a", "b"}, branch: "main"},
+		{args: []string{"--branch=main", "a"}, positional: []string{"a"}, branch: "main"},
+		{args: []string{"a", "--", "-q", "b"}, positional: []string{"a", "-q", "b"}},
+		{args: []string{"--", "--"}, positional: []string{"--"}},
+		{args: []string{"a", "-q", "-b", "main", "b"}, inOrder: true, positional: []string{"a", "-q", "-b", "main", "b"}, quiet: true, branch: "main"},
+		{args: []string{"-q", "a", "--", "-q", "b"}, inOrder: true, positional: []string{"-q", "a", "--", "-q", "b"}, quiet: true},
+		{args: []string{"--", "--"}, inOrder: true, positional: []string{"--", "--"}},
+	}
+
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		quiet := fs.Bool("q", false, "")
+		branch := fs.String("b", "", "")
+		fs.StringVar(branch, "branch", "", "")
+
+		positional, err := parseArgs(fs, tt.args, tt.inOrder)
+		if err != nil {
+			t.Fatalf("parseArgs(%q) failed: %v", tt.args, err)
+		}
+
+		if !slices.Equal(positional, tt.positional) || *quiet != tt.quiet || *branch != tt.branch {
+			t.Errorf("parseArgs(%q) = %q, -q=%v, -b=%q; want %q, -q=%v, -b=%q",
+				tt.args, positional, *quiet, *branch, tt.positional, tt.quiet, tt.branch)
+		}
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := parseArgs(fs, []string{"a", "--nope"}, false); err == nil {
+		t.Error("parseArgs accepted an unknown flag after a positional argument")
+	}
+}
+
+func TestPrintUsage(t *testing.T) {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	initCommand.setup(fs)
+
+	buf := new(bytes.Buffer)
+	initCommand.printUsage(fs, buf)
+	usage := buf.String()
+
+	if !strings.HasPrefix(usage, "usage: snap init [-q] [--bare]") {
+		t.Errorf("usage doesn't start with the synopsis:\n%s", usage)
+	}
+
+	for _, want := range []string{"\n  -q\t", "\n  -b string\n", "\n  --bare\n", "\n  --object-format string\n"} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("usage doesn't contain %q:\n%s", want, usage)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package snapcli
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/heiytor/snap/refs"
+	"github.com/heiytor/snap/repository"
+)
+
+// The completion commands are registered here rather than in [commands] because "__complete"
+// needs to iterate over [commands] itself.
+func init() {
+	commands = append(commands, completionCommand, completeCommand)
+}
+
+// completionScripts holds the script printed by "snap completion <shell>". Each one asks
+// "snap __complete" for candidates and falls back to file names when there are none.
+var completionScripts = map[string]string{
+	"bash": `_snap() {
+	local IFS=$'\n'
+	COMPREPLY=($(compgen -W "$(snap __complete -- "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+complete -o default -F _snap snap
+`,
+	"zsh": `#compdef snap
+_snap() {
+	local -a candidates
+	candidates=(${(f)"$(snap __complete -- "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	if (( ${#candidates} )); then
+		compadd -a candidates
+	else
+		_files
+	fi
+}
+compdef _snap snap
+`,
+	"fish": `function __snap_complete
+	set -l tokens (commandline -opc) (commandline -ct)
+	snap __complete -- $tokens[2..-1] 2>/dev/null
+end
+complete -c snap -a '(__snap_complete)'
+`,
+}
+
+var completionCommand = &command{
+	name:  "completion",
+	usage: "(bash|zsh|fish)",
+	short: "Generate the shell completion script",
+	setup: func(fs *flag.FlagSet) runFunc {
+		return func(e *env, args []string) error {
+			if len(args) != 1 {
+				return &usageError{msg: "expected exactly one shell"}
+			}
+
+			script, ok := completionScripts[args[0]]
+			if !ok {
+				return &usageError{msg: "unsupported shell: " + args[0]}
+			}
+
+			_, err := fmt.Fprint(e.stdout, script)
+			return err
+		}
+	},
+}
+
+var completeCommand = &command{
+	name:   "__complete",
+	usage:  "-- [<word>...] <partial-word>",
+	short:  "Print completion candidates for the words of a command line",
+	hidden: true,
+	setup: func(fs *flag.FlagSet) runFunc {
+		return func(e *env, args []string) error {
+			for _, candidate := range e.completions(args) {
+				fmt.Fprintln(e.stdout, candidate)
+			}
+
+			return nil
+		}
+	},
+}
+
+// completions returns the candidates for the last element of words, the command line being
+// completed without the program name. Candidates are subcommands, flags of the current command
+// or, for commands that take revisions, branch and tag names.
+func (e *env) completions(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	current, words := words[len(words)-1], words[:len(words)-1]
+
+	// Skip the global options in front of the command name. Their values are complete, so
+	// they are applied to find the same repository the command will use.
+	for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+		if len(words) > 1 && (words[0] == "-C" || words[0] == "-c") {
+			e.parseGlobalOptions(words[:2])
+			words = words[1:]
+		}
+
+		words = words[1:]
+	}
+
+	candidates := []string{}
+	switch {
+	case len(words) == 0 && strings.HasPrefix(current, "-"):
+		candidates = append(candidates, "-C", "-c", "--help")
+	case len(words) == 0:
+		for _, cmd := range commands {
+			if !cmd.hidden {
+				candidates = append(candidates, cmd.name)
+			}
+		}
+	default:
+		cmd := lookupCommand(words[0])
+		switch {
+		case cmd == nil:
+		case strings.HasPrefix(current, "-"):
+			if cmd.setup == nil {
+				break
+			}
+
+			fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+			cmd.setup(fs)
+			fs.VisitAll(func(f *flag.Flag) {
+				if len(f.Name) == 1 {
+					candidates = append(candidates, "-"+f.Name)
+				} else {
+					candidates = append(candidates, "--"+f.Name)
+				}
+			})
+		case cmd.completeRefs:
+			candidates = append(candidates, e.refNames()...)
+		}
+	}
+
+	matches := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	return matches
+}
+
+// refNames returns HEAD and the short names of the branches and tags of the repository
+// containing the working directory, or nothing when there's no repository.
+func (e *env) refNames() []string {
+	repo, err := repository.Open(e.dir, e.repositoryOptions()...)
+	if err != nil {
+		return nil
+	}
+
+	names := []string{"HEAD"}
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		refNames, err := refs.List(repo.FS, prefix)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range refNames {
+			names = append(names, strings.TrimPrefix(name, prefix))
+		}
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names)
+}
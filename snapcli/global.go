@@ -0,0 +1,92 @@
+package snapcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/heiytor/snap/repository"
+)
+
+// parseGlobalOptions consumes the options given before the command name and records them in e.
+// It stops at the first argument that is not an option, or at -h/--help, and returns the
+// remaining arguments.
+//
+//	-C <path>            run as if snap was started in <path>; relative paths stack
+//	-c <name>=<value>    override a configuration value, "-c <name>" sets it to true
+func (e *env) parseGlobalOptions(args []string) ([]string, error) {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		switch args[0] {
+		case "-h", "--help":
+			return args, nil
+		case "-C":
+			if len(args) < 2 {
+				return nil, &usageError{msg: "no directory given for '-C' option"}
+			}
+
+			if err := e.chdir(args[1]); err != nil {
+				return nil, err
+			}
+		case "-c":
+			if len(args) < 2 {
+				return nil, &usageError{msg: "-c expects a configuration string"}
+			}
+
+			key, value, ok := strings.Cut(args[1], "=")
+			if !ok {
+				value = "true"
+			}
+
+			e.config = append(e.config, [2]string{key, value})
+		default:
+			return nil, &usageError{msg: "unknown option: " + args[0]}
+		}
+
+		args = args[2:]
+	}
+
+	return args, nil
+}
+
+// chdir changes the directory commands run in, like "cd dir" would. An empty dir is ignored.
+func (e *env) chdir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	dir = e.path(dir)
+
+	file, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Errorf("cannot change to '%s': No such file or directory", dir)
+	case err != nil:
+		return fmt.Errorf("cannot change to '%s': %w", dir, err)
+	case !file.IsDir():
+		return fmt.Errorf("cannot change to '%s': Not a directory", dir)
+	}
+
+	e.dir = dir
+	return nil
+}
+
+// path resolves a path given on the command line against the directory set with -C.
+func (e *env) path(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(e.dir, path)
+}
+
+// repositoryOptions returns the options every command should pass to [repository.Open] and
+// [repository.Init], carrying the -c overrides.
+func (e *env) repositoryOptions() []repository.Option {
+	opts := make([]repository.Option, 0, len(e.config))
+	for _, kv := range e.config {
+		opts = append(opts, repository.WithConfig(kv[0], kv[1]))
+	}
+
+	return opts
+}
@@ -0,0 +1,56 @@
+package snapcli_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGlobalOptions(t *testing.T) {
+	runTests(t, []runTest{
+		{name: "missing -C value", args: []string{"-C"}, code: 129, stderr: "error: no directory given for '-C' option\n"},
+		{name: "missing -c value", args: []string{"-c"}, code: 129, stderr: "error: -c expects a configuration string\n"},
+		{name: "unknown option", args: []string{"--nope", "init"}, code: 129, stderr: "error: unknown option: --nope\n"},
+		{name: "missing directory", args: []string{"-C", "nope", "init"}, code: 128, stderr: "fatal: cannot change to '"},
+		{name: "key without section", args: []string{"-c", "core", "init"}, code: 128, stderr: "fatal: key does not contain a section: core\n"},
+		{name: "key without name", args: []string{"-c", "core.=x", "init"}, code: 128, stderr: "fatal: key does not contain variable name: core.\n"},
+	})
+
+	// -C options stack like successive "cd" commands.
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a", "b"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, stderr := run(dir, "-C", "a", "-C", "b", "init", "-q"); code != 0 {
+		t.Fatalf("init exited with %d: %s", code, stderr)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "a", "b", ".git", "HEAD")); err != nil {
+		t.Errorf("-C a -C b didn't initialize a/b: %v", err)
+	}
+
+	// -c overrides apply for the duration of the command and are not saved.
+	if code, _, stderr := run(dir, "-c", "init.defaultBranch=trunk", "-c", "core.hideDotFiles", "init", "-q", "c"); code != 0 {
+		t.Fatalf("init exited with %d: %s", code, stderr)
+	}
+
+	head, err := os.ReadFile(filepath.Join(dir, "c", ".git", "HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "ref: refs/heads/trunk\n"; string(head) != want {
+		t.Errorf("HEAD = %q; want %q", head, want)
+	}
+
+	config, err := os.ReadFile(filepath.Join(dir, "c", ".git", "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(config), "trunk") || strings.Contains(string(config), "hideDotFiles") {
+		t.Errorf("-c overrides were saved to the config file:\n%s", config)
+	}
+}
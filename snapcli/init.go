@@ -0,0 +1,62 @@
+package snapcli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/heiytor/snap/repository"
+)
+
+var initCommand = &command{
+	name:  "init",
+	usage: "[-q] [--bare] [-b <branch-name>] [--object-format=<format>] [<directory>]",
+	short: "Create an empty Git repository",
+	setup: func(fs *flag.FlagSet) runFunc {
+		quiet := fs.Bool("q", false, "only print error and warning messages")
+		bare := fs.Bool("bare", false, "create a bare repository")
+		branch := fs.String("b", "", "override the name of the initial branch")
+		fs.StringVar(branch, "initial-branch", "", "same as -b")
+		format := fs.String("object-format", "", "specify the hash algorithm to use, sha1 by default")
+
+		return func(e *env, args []string) error {
+			if len(args) > 1 {
+				return &usageError{msg: "too many arguments"}
+			}
+
+			path := "."
+			if len(args) == 1 && args[0] != "" {
+				path = args[0]
+			}
+
+			opts := e.repositoryOptions()
+			if *format != "" {
+				opts = append(opts, repository.WithObjectFormat(*format))
+			}
+			if *bare {
+				opts = append(opts, repository.WithBare())
+			}
+			if *branch != "" {
+				opts = append(opts, repository.WithDefaultBranch(*branch))
+			}
+
+			repo, err := repository.Init(e.path(path), opts...)
+			if err != nil {
+				return err
+			}
+
+			if repo.Reinitialized() && *branch != "" {
+				fmt.Fprintln(e.stderr, "warning: re-init: ignored --initial-branch="+*branch)
+			}
+
+			switch {
+			case *quiet:
+			case repo.Reinitialized():
+				fmt.Fprintf(e.stdout, "Reinitialized existing Git repository in %s/\n", repo.GitDir)
+			default:
+				fmt.Fprintf(e.stdout, "Initialized empty Git repository in %s/\n", repo.GitDir)
+			}
+
+			return nil
+		}
+	},
+}
@@ -0,0 +1,58 @@
+package snapcli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+	runTests(t, []runTest{
+		{name: "default branch", args: []string{"init", "-q"}, code: 0},
+		{name: "initial branch", args: []string{"init", "-q", "-b", "main"}, code: 0},
+		{name: "invalid branch", args: []string{"init", "-b", "a..b c"}, code: 128, stderr: "fatal: invalid initial branch name: 'a..b c'\n"},
+		{name: "unknown format", args: []string{"init", "--object-format=md5"}, code: 128, stderr: "fatal: unknown hash algorithm 'md5'\n"},
+	})
+
+	dir := t.TempDir()
+	if code, _, stderr := run(dir, "init", "-q", "--initial-branch", "feature/x", "repo"); code != 0 {
+		t.Fatalf("init exited with %d: %s", code, stderr)
+	}
+
+	head, err := os.ReadFile(filepath.Join(dir, "repo", ".git", "HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "ref: refs/heads/feature/x\n"; string(head) != want {
+		t.Errorf("HEAD = %q; want %q", head, want)
+	}
+
+	if code, _, _ := run(dir, "init", "-b", "bad..name", "other"); code == 0 {
+		t.Fatal("init accepted an invalid branch name")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "other")); !os.IsNotExist(err) {
+		t.Errorf("init with an invalid branch name created its directory: %v", err)
+	}
+}
+
+func TestReinit(t *testing.T) {
+	dir := t.TempDir()
+	if code, _, stderr := run(dir, "init", "-q", "--object-format=sha256"); code != 0 {
+		t.Fatalf("init exited with %d: %s", code, stderr)
+	}
+
+	code, stdout, stderr := run(dir, "init", "-b", "main")
+	if code != 0 || stdout != "Reinitialized existing Git repository in "+filepath.Join(dir, ".git")+"/\n" {
+		t.Errorf("init = %d, %q; want 0 and a reinitialized message", code, stdout)
+	}
+
+	if stderr != "warning: re-init: ignored --initial-branch=main\n" {
+		t.Errorf("stderr = %q; want a warning about the ignored branch", stderr)
+	}
+
+	if code, _, stderr := run(dir, "init", "--object-format=sha1"); code != 128 || stderr != "fatal: attempt to reinitialize repository with different hash\n" {
+		t.Errorf("init = %d, %q; want a fatal object format mismatch", code, stderr)
+	}
+}
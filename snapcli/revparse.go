@@ -0,0 +1,187 @@
+package snapcli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/heiytor/snap/refs"
+	"github.com/heiytor/snap/repository"
+)
+
+// abbrevFlag is "--short[=<length>]": given alone it selects the default length.
+type abbrevFlag struct {
+	length int
+}
+
+func (f *abbrevFlag) String() string   { return "" }
+func (f *abbrevFlag) IsBoolFlag() bool { return true }
+
+func (f *abbrevFlag) Set(value string) error {
+	if value == "true" {
+		f.length = 7
+		return nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("expects a numerical value")
+	}
+
+	// Like git, lengths are clamped to the 4 characters needed to be useful.
+	f.length = max(n, 4)
+	return nil
+}
+
+// revParseQueries are the introspection flags of rev-parse, answered by [env.revParseQuery].
+var revParseQueries = []struct{ name, usage string }{
+	{"show-toplevel", "show the absolute path of the top-level directory of the work tree"},
+	{"git-dir", "show the path to the git directory"},
+	{"absolute-git-dir", "like --git-dir, but always absolute"},
+	{"is-inside-work-tree", "print whether the current directory is inside the work tree"},
+	{"is-inside-git-dir", "print whether the current directory is inside the git directory"},
+	{"is-bare-repository", "print whether the repository is bare"},
+}
+
+var revParseCommand = &command{
+	name:         "rev-parse",
+	usage:        "[<options>] [<rev>...] [--] [<path>...]",
+	short:        "Pick out and massage parameters",
+	completeRefs: true,
+	inOrder:      true,
+	setup: func(fs *flag.FlagSet) runFunc {
+		queries := map[string]bool{}
+		for _, q := range revParseQueries {
+			queries[q.name] = true
+			fs.Bool(q.name, false, q.usage)
+		}
+
+		short := &abbrevFlag{}
+		fs.Var(short, "short", "abbreviate the object name of the single revision to 7 characters, or <n> with --short=<n>")
+
+		return func(e *env, args []string) error {
+			repo, err := repository.Open(e.dir, e.repositoryOptions()...)
+			if err != nil {
+				return err
+			}
+
+			// Like git, --short implies verifying a single revision, which is printed last.
+			verified := ""
+			for i, arg := range args {
+				// Like git, paths after "--" are printed unchanged, unless verifying.
+				if arg == "--" {
+					if short.length == 0 {
+						fmt.Fprintln(e.stdout, strings.Join(args[i:], "\n"))
+					}
+
+					break
+				}
+
+				if name, ok := strings.CutPrefix(arg, "-"); ok && name != "" {
+					if name = strings.TrimPrefix(name, "-"); !queries[name] {
+						continue
+					}
+
+					answer, err := e.revParseQuery(repo, name)
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintln(e.stdout, answer)
+					continue
+				}
+
+				oid, err := e.resolveRevision(repo, arg)
+				switch {
+				case short.length > 0 && (err != nil || verified != ""):
+					return errors.New("Needed a single revision")
+				case short.length > 0:
+					verified = oid[:min(short.length, len(oid))]
+				case err != nil:
+					return err
+				default:
+					fmt.Fprintln(e.stdout, oid)
+				}
+			}
+
+			if short.length > 0 {
+				if verified == "" {
+					return errors.New("Needed a single revision")
+				}
+
+				fmt.Fprintln(e.stdout, verified)
+			}
+
+			return nil
+		}
+	},
+}
+
+// revParseQuery answers one of the introspection flags of rev-parse, such as "git-dir".
+func (e *env) revParseQuery(repo *repository.GitRepository, query string) (string, error) {
+	bare := repo.IsBare()
+
+	switch query {
+	case "show-toplevel":
+		if repo.WorkTree == "" || bare {
+			return "", errors.New("this operation must be run in a work tree")
+		}
+
+		return repo.WorkTree, nil
+	case "git-dir":
+		// Like git, use the short form when running from the top of the repository.
+		switch e.dir {
+		case repo.GitDir:
+			return ".", nil
+		case repo.WorkTree:
+			if rel, err := filepath.Rel(e.dir, repo.GitDir); err == nil && !strings.HasPrefix(rel, "..") {
+				return rel, nil
+			}
+		}
+
+		return repo.GitDir, nil
+	case "absolute-git-dir":
+		return repo.GitDir, nil
+	case "is-inside-work-tree":
+		inside := !bare && isWithin(e.dir, repo.WorkTree) && !isWithin(e.dir, repo.GitDir)
+		return strconv.FormatBool(inside), nil
+	case "is-inside-git-dir":
+		return strconv.FormatBool(isWithin(e.dir, repo.GitDir)), nil
+	case "is-bare-repository":
+		return strconv.FormatBool(bare), nil
+	default:
+		return "", errors.New("unknown query: " + query)
+	}
+}
+
+// resolveRevision returns the object ID named by rev, either a full object ID or a reference.
+// Like git, broken references are reported with a warning and otherwise treated as missing.
+func (e *env) resolveRevision(repo *repository.GitRepository, rev string) (string, error) {
+	if refs.IsObjectID(rev, repo.ObjectFormat()) {
+		return strings.ToLower(rev), nil
+	}
+
+	oid, err := refs.Resolve(repo.FS, rev, repo.ObjectFormat())
+
+	var (
+		notFound *refs.NotFoundError
+		broken   *refs.BrokenRefError
+	)
+	if errors.As(err, &broken) {
+		fmt.Fprintln(e.stderr, "warning: ignoring broken ref", broken.Name)
+	}
+	if errors.As(err, &notFound) || errors.As(err, &broken) {
+		return "", fmt.Errorf("ambiguous argument '%s': unknown revision or path not in the working tree.", rev)
+	}
+
+	return oid, err
+}
+
+// isWithin reports whether path is dir or one of its descendants.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
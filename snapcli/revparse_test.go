@@ -0,0 +1,118 @@
+package snapcli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const oid = "1111111111111111111111111111111111111111"
+
+// initRepo creates a repository in dir whose master branch holds content, when given.
+func initRepo(content string) func(t *testing.T, dir string) {
+	return func(t *testing.T, dir string) {
+		if code, _, stderr := run(dir, "init", "-q"); code != 0 {
+			t.Fatalf("init exited with %d: %s", code, stderr)
+		}
+
+		if content == "" {
+			return
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, ".git", "refs", "heads", "master"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// bareConfig creates a repository in dir whose config sets core.bare to value.
+func bareConfig(value string) func(t *testing.T, dir string) {
+	return func(t *testing.T, dir string) {
+		initRepo("")(t, dir)
+
+		config, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer config.Close()
+
+		if _, err := config.WriteString("[core]\n\tbare = " + value + "\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRevParse(t *testing.T) {
+	runTests(t, []runTest{
+		{
+			name:   "queries",
+			setup:  initRepo(""),
+			args:   []string{"rev-parse", "--is-inside-work-tree", "--is-bare-repository", "--git-dir"},
+			code:   0,
+			stdout: "true\nfalse\n.git\n",
+		},
+		{
+			name:   "bare from config",
+			setup:  bareConfig("yes"),
+			args:   []string{"rev-parse", "--is-bare-repository", "--is-inside-work-tree"},
+			code:   0,
+			stdout: "true\nfalse\n",
+		},
+		{
+			name:   "show-toplevel in a bare repository",
+			setup:  bareConfig("1"),
+			args:   []string{"rev-parse", "--show-toplevel"},
+			code:   128,
+			stderr: "fatal: this operation must be run in a work tree\n",
+		},
+		{
+			name:   "in order",
+			setup:  initRepo(oid + "\n"),
+			args:   []string{"rev-parse", "HEAD", "--git-dir", "master"},
+			code:   0,
+			stdout: oid + "\n.git\n" + oid + "\n",
+		},
+		{
+			name:   "short",
+			setup:  initRepo(oid + "\n"),
+			args:   []string{"rev-parse", "--short=9", "HEAD", "--git-dir"},
+			code:   0,
+			stdout: ".git\n111111111\n",
+		},
+		{
+			name:   "paths",
+			setup:  initRepo(oid + "\n"),
+			args:   []string{"rev-parse", "--git-dir", "HEAD", "--", "foo", "-q", "HEAD"},
+			code:   0,
+			stdout: ".git\n" + oid + "\n--\nfoo\n-q\nHEAD\n",
+		},
+		{
+			name:   "short ignores paths",
+			setup:  initRepo(oid + "\n"),
+			args:   []string{"rev-parse", "--short", "HEAD", "--", "foo"},
+			code:   0,
+			stdout: "1111111\n",
+		},
+		{
+			name:   "short needs one revision",
+			setup:  initRepo(oid + "\n"),
+			args:   []string{"rev-parse", "--short", "HEAD", "master"},
+			code:   128,
+			stderr: "fatal: Needed a single revision\n",
+		},
+		{
+			name:   "unknown revision",
+			setup:  initRepo(oid + "\n"),
+			args:   []string{"rev-parse", "refs/../x"},
+			code:   128,
+			stderr: "fatal: ambiguous argument 'refs/../x': unknown revision",
+		},
+		{
+			name:   "broken ref",
+			setup:  initRepo("\n"),
+			args:   []string{"rev-parse", "HEAD"},
+			code:   128,
+			stderr: "warning: ignoring broken ref refs/heads/master\nfatal: ambiguous argument 'HEAD'",
+		},
+	})
+}
@@ -0,0 +1,98 @@
+// Package snapcli implements the snap command line. [Run] is what cmd/snap executes, and can be
+// called directly to embed snap in tests or other binaries.
+package snapcli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/heiytor/snap/trace"
+)
+
+// Run executes the snap command described by args, which does not include the program name.
+// Output is written to stdout and stderr, and the returned value is the process exit code:
+// 0 on success, 1 for errors, 128 for fatal errors and 129 for usage errors, like git.
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	e := &env{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	var err error
+	if e.dir, err = os.Getwd(); err != nil {
+		fmt.Fprintln(stderr, "fatal:", err)
+		return exitFatal
+	}
+
+	if args, err = e.parseGlobalOptions(args); err != nil {
+		var usageErr *usageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintln(stderr, "error:", err)
+			printCommands(stderr)
+			return exitUsage
+		}
+
+		fmt.Fprintln(stderr, "fatal:", err)
+		return exitFatal
+	}
+
+	if len(args) < 1 {
+		printCommands(stderr)
+		return exitError
+	}
+
+	help := false
+	switch args[0] {
+	case "-h", "--help":
+		printCommands(stdout)
+		return 0
+	case "help":
+		if len(args) < 2 {
+			printCommands(stdout)
+			return 0
+		}
+
+		help, args = true, []string{args[1], "-h"}
+	}
+
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "snap: '%s' is not a snap command. See 'snap --help'.\n", args[0])
+		return exitError
+	}
+
+	tracer := trace.New(os.Getenv("SNAP_TRACE"), stderr)
+	defer tracer.Close()
+
+	perf := trace.New(os.Getenv("SNAP_TRACE_PERFORMANCE"), stderr)
+	defer perf.Close()
+
+	tracer.Printf("trace: built-in: snap %s", trace.Quote(args))
+	endRegion := perf.Region("snap command: snap " + trace.Quote(args))
+
+	err = cmd.run(e, args[1:])
+	endRegion()
+
+	if err != nil {
+		var (
+			usageErr    *usageError
+			nonFatalErr *nonFatalError
+		)
+
+		switch {
+		case help && errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, flag.ErrHelp), errors.As(err, &usageErr):
+			return exitUsage
+		case errors.As(err, &nonFatalErr):
+			fmt.Fprintln(stderr, "error:", err)
+			return exitError
+		default:
+			fmt.Fprintln(stderr, "fatal:", err)
+			return exitFatal
+		}
+	}
+
+	return 0
+}
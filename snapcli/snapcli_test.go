@@ -0,0 +1,79 @@
+package snapcli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/heiytor/snap/snapcli"
+)
+
+// TestMain keeps the system and global configuration of the machine running the tests out of
+// them.
+func TestMain(m *testing.M) {
+	os.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	os.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	os.Exit(m.Run())
+}
+
+// runTest describes a snap invocation and what it must print.
+type runTest struct {
+	name   string
+	setup  func(t *testing.T, dir string)
+	args   []string // args are run from within a new directory, through -C.
+	code   int
+	stdout string // stdout is the exact expected output, unless empty.
+	stderr string // stderr is a prefix of the expected error output, which must be empty otherwise.
+}
+
+// runTests runs each test from a new temporary directory.
+func runTests(t *testing.T, tests []runTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.setup != nil {
+				tt.setup(t, dir)
+			}
+
+			code, stdout, stderr := run(dir, tt.args...)
+			if code != tt.code {
+				t.Errorf("exit code = %d; want %d\nstderr: %s", code, tt.code, stderr)
+			}
+
+			if tt.stdout != "" && stdout != tt.stdout {
+				t.Errorf("stdout = %q; want %q", stdout, tt.stdout)
+			}
+
+			if !strings.HasPrefix(stderr, tt.stderr) || tt.stderr == "" && stderr != "" {
+				t.Errorf("stderr = %q; want prefix %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+// run runs snap with args from dir, through -C, and returns its exit code and output.
+func run(dir string, args ...string) (int, string, string) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := snapcli.Run(context.Background(), append([]string{"-C", dir}, args...), nil, stdout, stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	runTests(t, []runTest{
+		{name: "no command", code: 1, stderr: "usage: snap "},
+		{name: "help", args: []string{"help"}, code: 0},
+		{name: "unknown command", args: []string{"nope"}, code: 1, stderr: "snap: 'nope' is not a snap command."},
+		{name: "not implemented", args: []string{"add"}, code: 1, stderr: "error: 'add' is not implemented yet\n"},
+		{name: "command help", args: []string{"init", "-h"}, code: 129},
+		{name: "help command", args: []string{"help", "init"}, code: 0},
+		{name: "unknown flag", args: []string{"init", "--nope"}, code: 129, stderr: "error: flag provided but not defined"},
+		{name: "usage error", args: []string{"var"}, code: 129, stderr: "error: expected exactly one variable\nusage: snap var"},
+		{name: "fatal error", args: []string{"rev-parse", "--git-dir"}, code: 128, stderr: "fatal: not a git repository"},
+	})
+}
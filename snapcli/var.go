@@ -0,0 +1,63 @@
+package snapcli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/heiytor/snap/repository"
+)
+
+// logicalVars maps the variables known to "snap var" to how they are resolved.
+var logicalVars = map[string]func(repo *repository.GitRepository) (string, error){
+	"GIT_AUTHOR_IDENT": func(repo *repository.GitRepository) (string, error) {
+		ident, err := repo.AuthorIdent()
+		return ident.String(), err
+	},
+	"GIT_COMMITTER_IDENT": func(repo *repository.GitRepository) (string, error) {
+		ident, err := repo.CommitterIdent()
+		return ident.String(), err
+	},
+	"GIT_EDITOR": func(repo *repository.GitRepository) (string, error) {
+		return repo.Editor()
+	},
+	"GIT_PAGER": func(repo *repository.GitRepository) (string, error) {
+		return repo.Pager(), nil
+	},
+}
+
+var varCommand = &command{
+	name:  "var",
+	usage: "<variable>",
+	short: "Show a Git logical variable",
+	setup: func(fs *flag.FlagSet) runFunc {
+		return func(e *env, args []string) error {
+			if len(args) != 1 {
+				return &usageError{msg: "expected exactly one variable"}
+			}
+
+			resolve, ok := logicalVars[args[0]]
+			if !ok {
+				return &usageError{msg: "unknown variable: " + args[0]}
+			}
+
+			// Like git, the variables are resolved from the global configuration alone outside
+			// of a repository.
+			repo, err := repository.Open(e.dir, e.repositoryOptions()...)
+			if errors.Is(err, repository.ErrGitRepositoryNotFound) {
+				repo, err = repository.OpenGlobal(e.repositoryOptions()...)
+			}
+			if err != nil {
+				return err
+			}
+
+			value, err := resolve(repo)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(e.stdout, value)
+			return nil
+		}
+	},
+}
@@ -0,0 +1,64 @@
+package snapcli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// globalIdent makes the global configuration give the user identity, and clears any given through
+// the environment.
+func globalIdent(t *testing.T, dir string) {
+	global := filepath.Join(dir, "..", "gitconfig")
+	if err := os.WriteFile(global, []byte("[user]\n\tname = Global User\n\temail = global@example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("GIT_AUTHOR_NAME", "")
+	t.Setenv("GIT_AUTHOR_EMAIL", "")
+	t.Setenv("GIT_AUTHOR_DATE", "@1112911993 -0700")
+	t.Setenv("EMAIL", "")
+}
+
+func TestVar(t *testing.T) {
+	runTests(t, []runTest{
+		{
+			name:   "outside a repository",
+			setup:  globalIdent,
+			args:   []string{"var", "GIT_AUTHOR_IDENT"},
+			code:   0,
+			stdout: "Global User <global@example.com> 1112911993 -0700\n",
+		},
+		{
+			name: "repository over global",
+			setup: func(t *testing.T, dir string) {
+				globalIdent(t, dir)
+				initRepo("")(t, dir)
+
+				config, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer config.Close()
+
+				if _, err := config.WriteString("[user]\n\tname = Local User\n"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			args:   []string{"var", "GIT_AUTHOR_IDENT"},
+			code:   0,
+			stdout: "Local User <global@example.com> 1112911993 -0700\n",
+		},
+		{
+			name: "pager",
+			setup: func(t *testing.T, dir string) {
+				t.Setenv("GIT_PAGER", "cat")
+			},
+			args:   []string{"var", "GIT_PAGER"},
+			code:   0,
+			stdout: "cat\n",
+		},
+		{name: "unknown variable", args: []string{"var", "NOPE"}, code: 129, stderr: "error: unknown variable: NOPE\n"},
+	})
+}
@@ -0,0 +1,101 @@
+// Package trace implements snap's debugging output. Each kind of output is enabled by an
+// environment variable, such as SNAP_TRACE or SNAP_TRACE_PERFORMANCE, whose value selects the
+// destination: "1", "2" or "true" write to stderr and an absolute path appends to that file.
+// Any other value, including an empty one, disables it.
+package trace
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Tracer writes trace messages to the destination selected by an environment variable. A nil
+// or disabled Tracer discards everything, so callers don't need to check [Tracer.Enabled]
+// before printing.
+type Tracer struct {
+	w io.Writer
+	c io.Closer
+}
+
+// New returns a [Tracer] configured by value, the content of its environment variable. stderr
+// is used when value asks for standard error. If the file named by value can't be opened, a
+// warning is written to stderr and the Tracer is disabled.
+func New(value string, stderr io.Writer) *Tracer {
+	switch strings.ToLower(value) {
+	case "1", "2", "true":
+		return &Tracer{w: stderr}
+	}
+
+	if !filepath.IsAbs(value) {
+		return &Tracer{}
+	}
+
+	f, err := os.OpenFile(value, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: could not open '%s' for tracing: %s\n", value, err)
+		return &Tracer{}
+	}
+
+	return &Tracer{w: f, c: f}
+}
+
+// Enabled reports whether messages written to t are kept.
+func (t *Tracer) Enabled() bool {
+	return t != nil && t.w != nil
+}
+
+// Printf writes a message, prefixed with the current time, as a single line.
+func (t *Tracer) Printf(format string, args ...any) {
+	if !t.Enabled() {
+		return
+	}
+
+	msg := time.Now().Format("15:04:05.000000") + " " + fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+
+	io.WriteString(t.w, msg)
+}
+
+// Region starts timing the named region and returns the function that ends it, writing the
+// elapsed time.
+func (t *Tracer) Region(name string) func() {
+	if !t.Enabled() {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		t.Printf("performance: %.9f s: %s", time.Since(start).Seconds(), name)
+	}
+}
+
+// Close releases the file written by t, if any.
+func (t *Tracer) Close() error {
+	if t == nil || t.c == nil {
+		return nil
+	}
+
+	return t.c.Close()
+}
+
+// Quote returns args as a single string where each argument is single-quoted when needed, so
+// traced command lines can be copied back into a shell.
+func Quote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+			quoted[i] = arg
+			continue
+		}
+
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+
+	return strings.Join(quoted, " ")
+}